@@ -0,0 +1,25 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"person-service/internal/enrichmentfake"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Stand-in for agify/genderize/nationalize, used by docker-compose and in CI without internet
+func main() {
+	log := logrus.New()
+	log.SetFormatter(&logrus.JSONFormatter{})
+
+	addr := os.Getenv("FAKE_ENRICHMENT_ADDR")
+	if addr == "" {
+		addr = ":8081"
+	}
+
+	log.Infof("Fake enrichment server started on %s", addr)
+	if err := http.ListenAndServe(addr, enrichmentfake.NewHandler()); err != nil {
+		log.Fatal("Failed to start fake enrichment server: ", err)
+	}
+}
@@ -2,16 +2,21 @@ package main
 
 import (
 	"context"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"net/http"
 	"os"
+	"os/signal"
 	_ "person-service/docs"
 	"person-service/internal/config"
 	"person-service/internal/handler"
+	"person-service/internal/names"
 	"person-service/internal/repository"
 	"person-service/internal/service"
+	"syscall"
 )
 
 // @title Person Service API
@@ -47,8 +52,11 @@ func main() {
 	}
 	gin.SetMode(cfg.GinMode)
 
+	// Cancelled on SIGINT/SIGTERM, which stops the workers and their outbound calls
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// Test db connection
-	ctx := context.Background()
 	if err := repository.RunMigrations(
 		ctx,
 		cfg.DatabaseDSN,
@@ -63,24 +71,123 @@ func main() {
 	defer db.Close()
 
 	personRepo := repository.NewPersonRepository(db, log)
-	personService := service.NewPersonService(personRepo, log)
+	personMergeRepo := repository.NewPersonMergeRepository(db, log)
+
+	enrichmentCacheRepo := repository.NewEnrichmentCacheRepository(db, log)
+	enrichmentCache := service.NewEnrichmentCache(enrichmentCacheRepo, cfg.EnrichmentCache, log)
+	enrichmentCallRepo := repository.NewEnrichmentCallRepository(db, log)
+	enrichmentClient := service.NewEnrichmentClient(cfg, enrichmentCache, enrichmentCallRepo, log)
+	normalizer, err := names.NewNormalizer(names.Scheme(cfg.NameTransliteration))
+	if err != nil {
+		log.Fatal("Invalid name transliteration: ", err)
+	}
+
+	var dataset *service.DatasetEnricher
+	if cfg.EnrichmentDataset.Path != "" {
+		if dataset, err = service.NewDatasetEnricher(cfg.EnrichmentDataset.Path, normalizer, log); err != nil {
+			log.Fatal("Failed to load enrichment dataset: ", err)
+		}
+		go dataset.Watch(ctx, cfg.EnrichmentDataset.ReloadInterval)
+	}
+
+	breakers := service.NewCircuitBreakers(cfg.CircuitBreaker)
+	enrichers := service.NewEnricherRegistry()
+	for _, e := range enrichmentClient.Enrichers() {
+		e = breakers.Wrap(e)
+		if dataset != nil && cfg.EnrichmentDataset.Fallback {
+			e = service.WithFallback(e, dataset)
+		}
+		enrichers.Register(e)
+	}
+	if dataset != nil {
+		enrichers.Register(dataset)
+	}
+	genderRules := service.DefaultGenderRules
+	if cfg.GenderRulesPath != "" {
+		if genderRules, err = service.LoadGenderRules(cfg.GenderRulesPath); err != nil {
+			log.Fatal("Failed to load gender rules: ", err)
+		}
+	}
+	morphology, err := service.NewMorphologyEnricher(genderRules, cfg.EnrichmentCountryID)
+	if err != nil {
+		log.Fatal("Invalid gender rules: ", err)
+	}
+	enrichers.Register(morphology)
+	if err := enrichers.Use(cfg.EnrichmentProviders...); err != nil {
+		log.Fatal("Invalid enrichment providers: ", err)
+	}
+
+	jobRepo := repository.NewEnrichmentJobRepository(db, log)
+	worker := service.NewEnrichmentWorker(personRepo, jobRepo, enrichers, cfg.EnrichmentJobs, log)
+	workerDone := make(chan struct{})
+	go func() {
+		defer close(workerDone)
+		worker.Run(ctx)
+	}()
+
+	purger := service.NewPersonPurger(personRepo, cfg.Purge, log)
+	go purger.Run(ctx)
+
+	personService := service.NewPersonService(personRepo, jobRepo, personMergeRepo, enrichers, normalizer, log)
 	personHandler := handler.NewPersonHandler(personService, log)
+	adminHandler := handler.NewAdminHandler(enrichmentCache, log)
+	enrichmentService := service.NewEnrichmentService(personRepo, jobRepo, enrichmentCallRepo, enrichers, normalizer, log)
+	enrichmentHandler := handler.NewEnrichmentHandler(enrichmentService, breakers, log)
+	duplicateService := service.NewDuplicateService(personRepo, personMergeRepo, cfg.Duplicates, log)
+	duplicateHandler := handler.NewDuplicateHandler(duplicateService, log)
 
 	// Init router
 	r := setupRouter()
+	r.GET("/metrics", handler.Metrics(breakers, enrichmentCache))
 
-	api := r.Group("/api")
+	api := r.Group("/api", handler.RequestTimeout(cfg.RequestTimeout), handler.IdentifyAdmin(cfg.AdminToken))
 	{
 		api.POST("/person", personHandler.CreatePerson)
 		api.GET("/person/:id", personHandler.GetPerson)
 		api.GET("/people", personHandler.GetAll)
+		api.GET("/people/search", personHandler.Search)
+		api.GET("/people/duplicates", duplicateHandler.FindDuplicates)
+		api.POST("/people/bulk", personHandler.CreatePeople)
 		api.PUT("/person/:id", personHandler.Update)
 		api.DELETE("/person/:id", personHandler.Delete)
+		api.POST("/person/:id/restore", personHandler.Restore)
+		api.POST("/person/:id/merge", duplicateHandler.Merge)
+		api.GET("/person/:id/merges", duplicateHandler.GetMerges)
+		api.POST("/person/:id/enrich", enrichmentHandler.EnrichPerson)
+		api.GET("/person/:id/enrichment-history", enrichmentHandler.GetHistory)
+		api.POST("/people/enrich", enrichmentHandler.EnrichPeople)
+		api.GET("/enrichment/status", enrichmentHandler.GetStatus)
+		api.GET("/enrichment/jobs/:id", enrichmentHandler.GetJob)
+		api.GET("/enrichment/batches/:id", enrichmentHandler.GetBatch)
+	}
+
+	admin := api.Group("/admin", handler.AdminAuth(cfg.AdminToken))
+	{
+		admin.GET("/enrichment-cache", adminHandler.GetEnrichmentCacheStats)
+		admin.DELETE("/enrichment-cache", adminHandler.PurgeEnrichmentCache)
 	}
 
 	// Load server
-	log.Infof("Server started on port %s", cfg.ServerPort)
-	if err := r.Run(":" + cfg.ServerPort); err != nil {
-		log.Fatal("Failed to start server: ", err)
+	server := &http.Server{
+		Addr:    ":" + cfg.ServerPort,
+		Handler: r,
+	}
+	go func() {
+		log.Infof("Server started on port %s", cfg.ServerPort)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Failed to start server: ", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Info("Shutting down server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.WithError(err).Error("Server shutdown failed")
 	}
+	<-workerDone
+	// Batches still being queued would otherwise stay pending forever
+	enrichmentService.Wait()
+	log.Info("Server stopped")
 }
@@ -15,59 +15,73 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/people": {
+        "/admin/enrichment-cache": {
             "get": {
-                "description": "Retrieves a list of persons with optional filters and pagination",
+                "description": "Returns hit/miss counters of the enrichment cache since the service started",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "persons"
+                    "admin"
                 ],
-                "summary": "Get all persons",
+                "summary": "Get enrichment cache statistics",
                 "parameters": [
-                    {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Page number",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "Page size",
-                        "name": "page_size",
-                        "in": "query"
-                    },
                     {
                         "type": "string",
-                        "description": "Filter by name",
-                        "name": "name",
-                        "in": "query"
+                        "description": "Admin token",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.EnrichmentCacheStats"
+                        }
                     },
-                    {
-                        "type": "string",
-                        "description": "Filter by surname",
-                        "name": "surname",
-                        "in": "query"
+                    "401": {
+                        "description": "Invalid admin token",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
                     },
+                    "403": {
+                        "description": "Admin API is disabled",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes cached provider answers, optionally only for one provider and/or name",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Purge the enrichment cache",
+                "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Filter by age",
-                        "name": "age",
-                        "in": "query"
+                        "type": "string",
+                        "description": "Admin token",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Filter by gender",
-                        "name": "gender",
+                        "description": "Provider name, e.g. agify",
+                        "name": "provider",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Filter by nationality",
-                        "name": "nationality",
+                        "description": "Person name, purged together with its country-specific answers",
+                        "name": "name",
                         "in": "query"
                     }
                 ],
@@ -75,11 +89,17 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.PersonListResponse"
+                            "$ref": "#/definitions/domain.PurgeEnrichmentCacheResponse"
                         }
                     },
-                    "400": {
-                        "description": "Invalid query parameters",
+                    "401": {
+                        "description": "Invalid admin token",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin API is disabled",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResponse"
                         }
@@ -93,39 +113,40 @@ const docTemplate = `{
                 }
             }
         },
-        "/person": {
-            "post": {
-                "description": "Creates a person with enriched age, gender, and nationality from external APIs",
-                "consumes": [
-                    "application/json"
-                ],
+        "/enrichment/batches/{id}": {
+            "get": {
+                "description": "Returns the batch with the number of queued, running, done and failed jobs",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "persons"
+                    "enrichment"
                 ],
-                "summary": "Create a new person",
+                "summary": "Get a bulk re-enrichment",
                 "parameters": [
                     {
-                        "description": "Person data",
-                        "name": "person",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.CreatePersonRequest"
-                        }
+                        "type": "integer",
+                        "description": "Batch ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Person"
+                            "$ref": "#/definitions/domain.EnrichmentBatch"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Batch not found",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResponse"
                         }
@@ -139,20 +160,19 @@ const docTemplate = `{
                 }
             }
         },
-        "/person/{id}": {
+        "/enrichment/jobs/{id}": {
             "get": {
-                "description": "Retrieves a person by their unique ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "persons"
+                    "enrichment"
                 ],
-                "summary": "Get a person by ID",
+                "summary": "Get an enrichment job",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Person ID",
+                        "description": "Job ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -162,7 +182,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Person"
+                            "$ref": "#/definitions/domain.EnrichmentJob"
                         }
                     },
                     "400": {
@@ -172,7 +192,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Person not found",
+                        "description": "Job not found",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResponse"
                         }
@@ -184,52 +204,206 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Updates a person's details by their ID",
-                "consumes": [
+            }
+        },
+        "/enrichment/status": {
+            "get": {
+                "description": "Returns the circuit breaker state (closed, open, half-open) of each enrichment provider",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "enrichment"
+                ],
+                "summary": "Get enrichment provider status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.EnrichmentProviderStatus"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/people": {
+            "get": {
+                "description": "Retrieves a list of persons with optional filters and pagination\nPages are selected by page and page_size, or by the cursors returned in meta and in the Link header.\nA cursor is bound to the sort it was issued for; filters should stay the same while paging.\nWith page and page_size, meta always has page and total_items, unless count=none was requested.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "persons"
                 ],
-                "summary": "Update a person",
+                "summary": "Get all persons",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Person ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
                     },
                     {
-                        "description": "Person data",
-                        "name": "person",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.UpdatePersonRequest"
-                        }
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor from meta.next_cursor or meta.prev_cursor; replaces page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Page size for cursor pagination, same as page_size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "exact",
+                            "estimated",
+                            "none"
+                        ],
+                        "type": "string",
+                        "default": "exact",
+                        "description": "How meta.total_items is counted: exact, estimated from planner statistics, or not at all (none leaves total_items out)",
+                        "name": "count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "-created_at",
+                        "description": "Comma-separated sort fields (id, name, surname, age, gender, nationality, created_at), prefix - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by name (substring)",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by surname (substring)",
+                        "name": "surname",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only people with (true) or without (false) a patronymic",
+                        "name": "has_patronymic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by exact age",
+                        "name": "age",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum age, inclusive",
+                        "name": "age_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum age, inclusive",
+                        "name": "age_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated genders, e.g. male,other",
+                        "name": "gender",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated ISO 3166-1 alpha-2 codes, exact match, e.g. RU,UA",
+                        "name": "nationality",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "partial",
+                            "complete",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "description": "Filter by enrichment status",
+                        "name": "enrichment_status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only fully enriched people (true) or the rest (false)",
+                        "name": "enriched",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum gender probability (0..1)",
+                        "name": "gender_probability_gte",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum probability of the stored nationality (0..1)",
+                        "name": "nationality_probability_gte",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after, RFC 3339 timestamp or date (2006-01-02)",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before the timestamp, or on or before the date",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also list deleted people; requires X-Admin-Token",
+                        "name": "include_deleted",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Person"
+                            "$ref": "#/definitions/domain.PersonListResponse"
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "RFC 8288 links to the next and prev pages"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Invalid request body or ID",
+                        "description": "Invalid query parameters",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Person not found",
+                    "403": {
+                        "description": "include_deleted without admin token",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResponse"
                         }
@@ -241,37 +415,41 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Deletes a person by their ID",
+            }
+        },
+        "/people/bulk": {
+            "post": {
+                "description": "Creates up to 10000 people in one transaction and queues their enrichment.\nWorkers enrich queued people in groups, sending up to 10 names per provider call.\nEach person takes the same known values and \"enrich\" option as POST /person.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "persons"
                 ],
-                "summary": "Delete a person",
+                "summary": "Create people in bulk",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Person ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "People to create",
+                        "name": "people",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.BulkCreatePeopleRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
-                    },
-                    "400": {
-                        "description": "Invalid ID format",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/domain.ErrorResponse"
+                            "$ref": "#/definitions/domain.BulkCreatePeopleResponse"
                         }
                     },
-                    "404": {
-                        "description": "Person not found",
+                    "400": {
+                        "description": "Invalid request body",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResponse"
                         }
@@ -284,24 +462,1079 @@ const docTemplate = `{
                     }
                 }
             }
-        }
+        },
+        "/people/duplicates": {
+            "get": {
+                "description": "Returns pairs of people whose normalized names match, most similar first. The older person of a pair\nis the suggested survivor for POST /person/{id}/merge. Patronymics only count when both people have one.\nWithout parameters the matching configured by DUPLICATE_MATCH_FIELDS and DUPLICATE_MIN_SIMILARITY is used.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "duplicates"
+                ],
+                "summary": "Find duplicate persons",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated names to match: name, surname, patronymic; name or surname is required",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Trigram similarity (0..1) each field needs, 1 for equal names",
+                        "name": "min_similarity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Maximum number of pairs",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of pairs to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.DuplicatesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/people/enrich": {
+            "post": {
+                "description": "Queues a refresh of the given fields for every person matching the same filters as GET /people.\nManually set fields are kept unless \"force\" is true.\nProgress is reported by GET /enrichment/batches/{id}.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "enrichment"
+                ],
+                "summary": "Re-enrich matching people",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by name (substring)",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by surname (substring)",
+                        "name": "surname",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only people with (true) or without (false) a patronymic",
+                        "name": "has_patronymic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by exact age",
+                        "name": "age",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum age, inclusive",
+                        "name": "age_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum age, inclusive",
+                        "name": "age_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated genders, e.g. male,other",
+                        "name": "gender",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated ISO 3166-1 alpha-2 codes, exact match, e.g. RU,UA",
+                        "name": "nationality",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "partial",
+                            "complete",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "description": "Filter by enrichment status",
+                        "name": "enrichment_status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only fully enriched people (true) or the rest (false)",
+                        "name": "enriched",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum gender probability (0..1)",
+                        "name": "gender_probability_gte",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum probability of the stored nationality (0..1)",
+                        "name": "nationality_probability_gte",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after, RFC 3339 timestamp or date (2006-01-02)",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before the timestamp, or on or before the date",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Fields to refresh",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.EnrichRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/domain.EnrichmentBatch"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameters or body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/people/search": {
+            "get": {
+                "description": "Fuzzy search over name, surname and patronymic, in their original and transliterated forms.\nHits are people whose names are similar enough to q (trigram word similarity, tolerates typos)\nor contain all of its words. Results are ranked by similarity, full word matches first.\nThe list filters of GET /people can narrow the search.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Search persons by name",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search query, e.g. Dmitriy Ivanov",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Maximum number of results",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "default": 0.4,
+                        "description": "Minimum word similarity (0..1) of a hit",
+                        "name": "min_similarity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated genders, e.g. male,other",
+                        "name": "gender",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated ISO 3166-1 alpha-2 codes, exact match, e.g. RU,UA",
+                        "name": "nationality",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum age, inclusive",
+                        "name": "age_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum age, inclusive",
+                        "name": "age_max",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.PersonSearchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/person": {
+            "post": {
+                "description": "Creates a person and queues enrichment of age, gender, and nationality from external APIs.\nThe person is returned with enrichment_status \"pending\"; poll GET /person/{id} for the enriched fields.\nAge and gender are localized to country_id (or the service default), see age_localization and gender_localization.\nSupplied age, gender and nationality are stored as set manually and not looked up.\n\"enrich\" limits the lookup: \"all\" (default), \"none\" or a list of fields such as [\"age\"].",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Create a new person",
+                "parameters": [
+                    {
+                        "description": "Person data",
+                        "name": "person",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.CreatePersonRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Person"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/person/{id}": {
+            "get": {
+                "description": "Retrieves a person by their unique ID. IDs of people merged into another one redirect to the survivor.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Get a person by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Person ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also return a deleted person; requires X-Admin-Token",
+                        "name": "include_deleted",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Person"
+                        }
+                    },
+                    "308": {
+                        "description": "Person was merged, see Location"
+                    },
+                    "400": {
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "include_deleted without admin token",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Person not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Updates a person's details by their ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Update a person",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Person ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Person data",
+                        "name": "person",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UpdatePersonRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Person"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or ID",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Person not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes a person by their ID. The person can be restored with POST /person/{id}/restore\nuntil it is purged after the retention period (PURGE_RETENTION).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Delete a person",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Person ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Person not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/person/{id}/enrich": {
+            "post": {
+                "description": "Queues a refresh of age, gender and/or nationality from the providers, overwriting the stored values.\nWithout a body all enrichable fields are refreshed. Fields set manually through PUT /person/{id}\nare kept unless \"force\" is true.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "enrichment"
+                ],
+                "summary": "Re-enrich a person",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Person ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to refresh",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.EnrichRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/domain.EnrichmentJob"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or ID",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Person not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/person/{id}/enrichment-history": {
+            "get": {
+                "description": "Returns the calls made to the enrichment providers for the person, newest first, with status code,\nlatency, error and the raw JSON answer. A batched call lists every name it asked for.\nAnswers served from the cache are not calls and do not show up.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "enrichment"
+                ],
+                "summary": "Get the enrichment history of a person",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Person ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of calls (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.EnrichmentCall"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Person not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/person/{id}/merge": {
+            "post": {
+                "description": "Merges the duplicate into the person of the URL, the survivor. The survivor takes over the duplicate's\npatronymic, country, age, gender and nationality where it has none, and manually set values over predicted ones.\nThe duplicate is deleted until purged, and restoring it undoes the merge; the merge is recorded and\nGET /person/{duplicate_id} redirects to the survivor.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "duplicates"
+                ],
+                "summary": "Merge a duplicate into a person",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Survivor ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Duplicate to merge",
+                        "name": "merge",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.MergePersonRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.MergePersonResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or ID",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Person not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/person/{id}/merges": {
+            "get": {
+                "description": "Returns the audit trail of duplicates merged into the person, or into people merged into it, newest\nfirst, each with the fields taken over and the duplicate as it was before the merge. Merges undone by\nrestoring the duplicate stay in the trail with undone_at set.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "duplicates"
+                ],
+                "summary": "Get the merges into a person",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Person ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.PersonMerge"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Person not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/person/{id}/restore": {
+            "post": {
+                "description": "Restores a person deleted with DELETE /person/{id} that has not been purged yet.\nRestoring a person that is not deleted is a conflict. Restoring a merged duplicate undoes the merge,\nwhile the survivor keeps the fields it took over.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Restore a deleted person",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Person ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Person"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Person not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Person is not deleted",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
     },
     "definitions": {
+        "domain.BulkCreatePeopleRequest": {
+            "type": "object",
+            "required": [
+                "people"
+            ],
+            "properties": {
+                "people": {
+                    "type": "array",
+                    "maxItems": 10000,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/domain.CreatePersonRequest"
+                    }
+                }
+            }
+        },
+        "domain.BulkCreatePeopleResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "ids": {
+                    "description": "IDs of the created people, in request order",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "domain.CountryProbability": {
+            "type": "object",
+            "properties": {
+                "country_id": {
+                    "type": "string"
+                },
+                "probability": {
+                    "type": "number"
+                }
+            }
+        },
         "domain.CreatePersonRequest": {
             "type": "object",
-            "required": [
-                "name",
-                "surname"
-            ],
+            "required": [
+                "name",
+                "surname"
+            ],
+            "properties": {
+                "age": {
+                    "description": "Age, Gender and Nationality are known values; they are stored as set manually and never looked up",
+                    "type": "integer",
+                    "maximum": 120,
+                    "minimum": 0
+                },
+                "country_id": {
+                    "description": "CountryID localizes the age and gender predictions, e.g. \"RU\"; the service default is used when empty",
+                    "type": "string",
+                    "example": "RU"
+                },
+                "enrich": {
+                    "description": "Enrich is \"all\" (the default), \"none\" or a list of the fields to look up, e.g. [\"age\"]",
+                    "type": "string",
+                    "example": "all"
+                },
+                "gender": {
+                    "type": "string",
+                    "enum": [
+                        "male",
+                        "female",
+                        "other"
+                    ]
+                },
+                "name": {
+                    "type": "string"
+                },
+                "nationality": {
+                    "type": "string",
+                    "example": "RU"
+                },
+                "patronymic": {
+                    "type": "string"
+                },
+                "surname": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.DuplicatePair": {
+            "type": "object",
+            "properties": {
+                "duplicate": {
+                    "$ref": "#/definitions/domain.Person"
+                },
+                "person": {
+                    "$ref": "#/definitions/domain.Person"
+                },
+                "similarity": {
+                    "description": "Similarity is the lowest similarity of the matched fields, 1 when they are equal",
+                    "type": "number"
+                }
+            }
+        },
+        "domain.DuplicatesResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.DuplicatePair"
+                    }
+                }
+            }
+        },
+        "domain.EnrichRequest": {
+            "type": "object",
+            "properties": {
+                "fields": {
+                    "description": "Fields to refresh; all enrichable fields when empty",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "force": {
+                    "description": "Force also refreshes fields that were set manually",
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.EnrichmentBatch": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "done": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "filters": {
+                    "$ref": "#/definitions/domain.PersonFilter"
+                },
+                "force": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "queued": {
+                    "type": "integer"
+                },
+                "running": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "enqueuing",
+                        "running",
+                        "done",
+                        "failed"
+                    ]
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.EnrichmentCacheStats": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "integer"
+                },
+                "memory_hits": {
+                    "type": "integer"
+                },
+                "misses": {
+                    "type": "integer"
+                },
+                "store_hits": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.EnrichmentCall": {
+            "type": "object",
+            "properties": {
+                "country_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "latency_ms": {
+                    "type": "integer"
+                },
+                "names": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "person_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "response": {
+                    "type": "object"
+                },
+                "status_code": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.EnrichmentJob": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "batch_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "force": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "person_id": {
+                    "type": "integer"
+                },
+                "providers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "refresh": {
+                    "type": "boolean"
+                },
+                "run_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "queued",
+                        "running",
+                        "done",
+                        "failed"
+                    ]
+                }
+            }
+        },
+        "domain.EnrichmentProviderStatus": {
+            "type": "object",
             "properties": {
-                "name": {
-                    "type": "string"
+                "consecutive_failures": {
+                    "type": "integer"
                 },
-                "patronymic": {
+                "opened_at": {
                     "type": "string"
                 },
-                "surname": {
+                "provider": {
                     "type": "string"
+                },
+                "state": {
+                    "type": "string",
+                    "enum": [
+                        "closed",
+                        "open",
+                        "half-open"
+                    ]
                 }
             }
         },
@@ -313,16 +1546,113 @@ const docTemplate = `{
                 }
             }
         },
+        "domain.FieldProvenance": {
+            "type": "object",
+            "properties": {
+                "confidence": {
+                    "type": "number"
+                },
+                "fetched_at": {
+                    "type": "string"
+                },
+                "source": {
+                    "description": "Source is the provider name, \"patronymic\"/\"surname\" for morphology rules or \"manual\"",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.FloatFilter": {
+            "type": "object",
+            "properties": {
+                "max": {
+                    "type": "number"
+                },
+                "min": {
+                    "type": "number"
+                },
+                "not": {
+                    "type": "boolean"
+                },
+                "null": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.IntFilter": {
+            "type": "object",
+            "properties": {
+                "eq": {
+                    "type": "integer"
+                },
+                "in": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "max": {
+                    "type": "integer"
+                },
+                "min": {
+                    "type": "integer"
+                },
+                "not": {
+                    "type": "boolean"
+                },
+                "null": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.MergePersonRequest": {
+            "type": "object",
+            "required": [
+                "duplicate_id"
+            ],
+            "properties": {
+                "duplicate_id": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "domain.MergePersonResponse": {
+            "type": "object",
+            "properties": {
+                "merge": {
+                    "$ref": "#/definitions/domain.PersonMerge"
+                },
+                "person": {
+                    "$ref": "#/definitions/domain.Person"
+                }
+            }
+        },
         "domain.PaginationMeta": {
             "type": "object",
             "properties": {
+                "count_mode": {
+                    "type": "string",
+                    "enum": [
+                        "exact",
+                        "estimated",
+                        "none"
+                    ]
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
                 "page": {
+                    "description": "Page is always set for page based pagination and left out for cursor based pagination",
                     "type": "integer"
                 },
                 "page_size": {
                     "type": "integer"
                 },
+                "prev_cursor": {
+                    "type": "string"
+                },
                 "total_items": {
+                    "description": "TotalItems is left out only when count=none was requested and is approximate when CountMode is \"estimated\"",
                     "type": "integer"
                 }
             }
@@ -337,9 +1667,28 @@ const docTemplate = `{
                 "age": {
                     "type": "integer"
                 },
+                "age_localization": {
+                    "type": "string"
+                },
+                "country_id": {
+                    "type": "string"
+                },
                 "createdAt": {
                     "type": "string"
                 },
+                "deleted_at": {
+                    "description": "DeletedAt is set for deleted people, which are purged after the retention period",
+                    "type": "string"
+                },
+                "enrichment_status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "partial",
+                        "complete",
+                        "failed"
+                    ]
+                },
                 "gender": {
                     "type": "string",
                     "enum": [
@@ -348,22 +1697,100 @@ const docTemplate = `{
                         "other"
                     ]
                 },
+                "gender_count": {
+                    "type": "integer"
+                },
+                "gender_localization": {
+                    "type": "string"
+                },
+                "gender_probability": {
+                    "type": "number"
+                },
+                "gender_source": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
                 "name": {
                     "type": "string"
                 },
+                "name_normalized": {
+                    "type": "string"
+                },
+                "nationalities": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.CountryProbability"
+                    }
+                },
                 "nationality": {
                     "type": "string",
                     "maxLength": 100,
                     "minLength": 2
                 },
+                "nationality_probability": {
+                    "type": "number"
+                },
                 "patronymic": {
                     "type": "string"
                 },
+                "patronymic_normalized": {
+                    "type": "string"
+                },
+                "provenance": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/domain.FieldProvenance"
+                    }
+                },
                 "surname": {
                     "type": "string"
+                },
+                "surname_normalized": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.PersonFilter": {
+            "type": "object",
+            "properties": {
+                "age": {
+                    "$ref": "#/definitions/domain.IntFilter"
+                },
+                "country_id": {
+                    "$ref": "#/definitions/domain.StringFilter"
+                },
+                "created_at": {
+                    "$ref": "#/definitions/domain.TimeFilter"
+                },
+                "enrichment_status": {
+                    "$ref": "#/definitions/domain.StringFilter"
+                },
+                "gender": {
+                    "$ref": "#/definitions/domain.StringFilter"
+                },
+                "gender_probability": {
+                    "$ref": "#/definitions/domain.FloatFilter"
+                },
+                "include_deleted": {
+                    "description": "IncludeDeleted also selects deleted people, which are left out by default",
+                    "type": "boolean"
+                },
+                "name": {
+                    "$ref": "#/definitions/domain.StringFilter"
+                },
+                "nationality": {
+                    "$ref": "#/definitions/domain.StringFilter"
+                },
+                "nationality_probability": {
+                    "$ref": "#/definitions/domain.FloatFilter"
+                },
+                "patronymic": {
+                    "$ref": "#/definitions/domain.StringFilter"
+                },
+                "surname": {
+                    "$ref": "#/definitions/domain.StringFilter"
                 }
             }
         },
@@ -381,6 +1808,207 @@ const docTemplate = `{
                 }
             }
         },
+        "domain.PersonMerge": {
+            "type": "object",
+            "properties": {
+                "fields": {
+                    "description": "Fields lists the survivor's fields taken over from the duplicate",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "merged": {
+                    "description": "Merged is the duplicate as it was before the merge",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Person"
+                        }
+                    ]
+                },
+                "merged_at": {
+                    "type": "string"
+                },
+                "merged_id": {
+                    "type": "integer"
+                },
+                "survivor_id": {
+                    "type": "integer"
+                },
+                "undone_at": {
+                    "description": "UndoneAt is set once the duplicate was restored; the merge no longer leads to the survivor",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.PersonSearchResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.PersonSearchResult"
+                    }
+                }
+            }
+        },
+        "domain.PersonSearchResult": {
+            "type": "object",
+            "required": [
+                "name",
+                "surname"
+            ],
+            "properties": {
+                "age": {
+                    "type": "integer"
+                },
+                "age_localization": {
+                    "type": "string"
+                },
+                "country_id": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "DeletedAt is set for deleted people, which are purged after the retention period",
+                    "type": "string"
+                },
+                "enrichment_status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "partial",
+                        "complete",
+                        "failed"
+                    ]
+                },
+                "gender": {
+                    "type": "string",
+                    "enum": [
+                        "male",
+                        "female",
+                        "other"
+                    ]
+                },
+                "gender_count": {
+                    "type": "integer"
+                },
+                "gender_localization": {
+                    "type": "string"
+                },
+                "gender_probability": {
+                    "type": "number"
+                },
+                "gender_source": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "name_normalized": {
+                    "type": "string"
+                },
+                "nationalities": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.CountryProbability"
+                    }
+                },
+                "nationality": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 2
+                },
+                "nationality_probability": {
+                    "type": "number"
+                },
+                "patronymic": {
+                    "type": "string"
+                },
+                "patronymic_normalized": {
+                    "type": "string"
+                },
+                "provenance": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/domain.FieldProvenance"
+                    }
+                },
+                "similarity": {
+                    "description": "Similarity is the trigram word similarity of the query to the person's names, 1 for a full match",
+                    "type": "number"
+                },
+                "surname": {
+                    "type": "string"
+                },
+                "surname_normalized": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.PurgeEnrichmentCacheResponse": {
+            "type": "object",
+            "properties": {
+                "purged": {
+                    "description": "Purged counts the entries dropped from memory and deleted from the database; an entry held in both counts twice",
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.StringFilter": {
+            "type": "object",
+            "properties": {
+                "contains": {
+                    "description": "Contains matches case-insensitively anywhere in the value",
+                    "type": "string"
+                },
+                "eq": {
+                    "type": "string"
+                },
+                "in": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "normalized": {
+                    "description": "Normalized is the normalized key of Contains. When set, the normalized form of the name may match instead.",
+                    "type": "string"
+                },
+                "not": {
+                    "type": "boolean"
+                },
+                "null": {
+                    "description": "Null selects rows without a value (true) or with one (false). Empty strings count as no value.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.TimeFilter": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "not": {
+                    "type": "boolean"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
         "domain.UpdatePersonRequest": {
             "type": "object",
             "properties": {
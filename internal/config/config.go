@@ -5,6 +5,50 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultAgifyURL          = "https://api.agify.io"
+	DefaultGenderizeURL      = "https://api.genderize.io"
+	DefaultNationalizeURL    = "https://api.nationalize.io"
+	DefaultEnrichmentTimeout = 5 * time.Second
+
+	DefaultRetryMaxAttempts = 3
+	DefaultRetryBaseDelay   = 200 * time.Millisecond
+	DefaultRetryMaxDelay    = 5 * time.Second
+
+	DefaultBreakerFailureThreshold = 5
+	DefaultBreakerOpenTimeout      = 30 * time.Second
+	DefaultBreakerHalfOpenRequests = 1
+
+	DefaultRequestTimeout     = 30 * time.Second
+	DefaultShutdownTimeout    = 15 * time.Second
+	DefaultEnrichmentDeadline = 15 * time.Second
+
+	DefaultEnrichmentWorkers = 4
+	DefaultJobPollInterval   = time.Second
+	DefaultJobMaxAttempts    = 10
+	DefaultJobRetryDelay     = 30 * time.Second
+	DefaultJobMaxRetryDelay  = time.Hour
+	DefaultJobLockTimeout    = 5 * time.Minute
+	DefaultJobBatchSize      = 50
+
+	DefaultNameTransliteration = "icao"
+
+	DefaultDatasetReloadInterval = 30 * time.Second
+
+	DefaultEnrichmentCacheTTL  = 30 * 24 * time.Hour
+	DefaultEnrichmentCacheSize = 10000
+
+	DefaultPurgeRetention = 30 * 24 * time.Hour
+	DefaultPurgeInterval  = time.Hour
+
+	DefaultDuplicateMatchFields   = "name,surname,patronymic"
+	DefaultDuplicateMinSimilarity = 1.0
 )
 
 type Config struct {
@@ -12,6 +56,104 @@ type Config struct {
 	DatabaseURL string
 	DatabaseDSN string
 	GinMode     string
+	// RequestTimeout bounds the handling of every API request, including the database calls it makes
+	RequestTimeout  time.Duration
+	ShutdownTimeout time.Duration
+	// AdminToken guards the /api/admin endpoints; they are disabled when it is empty
+	AdminToken string
+
+	// EnrichmentProviders lists the enabled enrichment providers in precedence order
+	EnrichmentProviders []string
+	// GenderRulesPath is a JSON file replacing the built-in patronymic and surname gender rules
+	GenderRulesPath string
+	// NameTransliteration is the Cyrillic to Latin scheme for enrichment and search keys: none, icao or gost
+	NameTransliteration string
+	// EnrichmentCountryID localizes age and gender of people created without a country hint; empty means global data
+	EnrichmentCountryID string
+	Agify               ProviderConfig
+	Genderize           ProviderConfig
+	Nationalize         ProviderConfig
+	EnrichmentCache     CacheConfig
+	CircuitBreaker      BreakerConfig
+	EnrichmentJobs      JobConfig
+	EnrichmentDataset   DatasetConfig
+	Duplicates          DuplicateConfig
+	Purge               PurgeConfig
+}
+
+// ProviderConfig describes how to reach a single external enrichment API
+type ProviderConfig struct {
+	BaseURL string
+	Timeout time.Duration
+	APIKey  string
+	Retry   RetryConfig
+}
+
+// RetryConfig is the retry budget of a provider. Waits longer than MaxDelay,
+// including Retry-After and rate limit resets, are not waited for.
+type RetryConfig struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// BreakerConfig holds the thresholds of the per-provider circuit breakers
+type BreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures that opens the circuit
+	FailureThreshold int
+	// OpenTimeout is how long an open circuit rejects calls before letting probes through
+	OpenTimeout time.Duration
+	// HalfOpenRequests is the number of concurrent probes allowed while half-open
+	HalfOpenRequests int
+}
+
+// JobConfig controls the asynchronous enrichment workers and their Postgres-backed queue
+type JobConfig struct {
+	Workers      int
+	PollInterval time.Duration
+	// Deadline bounds the enrichment of one claimed group of jobs as a whole, across providers and retries.
+	// Single provider calls are additionally bounded by ProviderConfig.Timeout.
+	Deadline    time.Duration
+	MaxAttempts int
+	// RetryDelay doubles with every failed attempt up to MaxRetryDelay
+	RetryDelay    time.Duration
+	MaxRetryDelay time.Duration
+	// LockTimeout is how long a running job may go without finishing before another worker takes it over
+	LockTimeout time.Duration
+	// BatchSize is how many jobs a worker claims at once; their names share batched provider calls
+	BatchSize int
+}
+
+// DatasetConfig describes the local name statistics served by the offline "dataset" provider.
+// The provider is available when Path is set.
+type DatasetConfig struct {
+	// Path is a .csv or .json file; it is reloaded when it changes
+	Path           string
+	ReloadInterval time.Duration
+	// Fallback lets the dataset answer for remote providers whose call failed
+	Fallback bool
+}
+
+// DuplicateConfig is the default matching of duplicate detection
+type DuplicateConfig struct {
+	// Fields are the names compared by their normalized forms: name, surname and/or patronymic
+	Fields []string
+	// MinSimilarity is the trigram similarity each field needs; 1 requires equal normalized forms
+	MinSimilarity float64
+}
+
+// PurgeConfig controls the removal of deleted people for good. A zero Retention disables purging.
+type PurgeConfig struct {
+	// Retention is how long deleted people can still be restored
+	Retention time.Duration
+	Interval  time.Duration
+}
+
+// CacheConfig controls the enrichment cache. A zero TTL disables caching.
+type CacheConfig struct {
+	TTL time.Duration
+	// Size is the number of entries kept in the in-process LRU in front of the database
+	Size int
 }
 
 func Load() (*Config, error) {
@@ -25,6 +167,12 @@ func Load() (*Config, error) {
 		DatabaseURL: os.Getenv("DATABASE_URL"),
 		DatabaseDSN: os.Getenv("DATABASE_DSN"),
 		GinMode:     os.Getenv("GIN_MODE"),
+		AdminToken:  os.Getenv("ADMIN_TOKEN"),
+
+		EnrichmentProviders: splitList(os.Getenv("ENRICHMENT_PROVIDERS")),
+		EnrichmentCountryID: strings.ToUpper(strings.TrimSpace(os.Getenv("ENRICHMENT_COUNTRY_ID"))),
+		NameTransliteration: strings.ToLower(strings.TrimSpace(os.Getenv("NAME_TRANSLITERATION"))),
+		GenderRulesPath:     os.Getenv("ENRICHMENT_GENDER_RULES_PATH"),
 	}
 
 	//Set default values
@@ -40,5 +188,201 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("GIN_MODE is not set")
 	}
 
+	if len(config.EnrichmentProviders) == 0 {
+		config.EnrichmentProviders = []string{"agify", "genderize", "nationalize", "morphology"}
+	}
+	if config.NameTransliteration == "" {
+		config.NameTransliteration = DefaultNameTransliteration
+	}
+	if country := config.EnrichmentCountryID; country != "" && len(country) != 2 {
+		return nil, fmt.Errorf("invalid ENRICHMENT_COUNTRY_ID %q: expected an ISO 3166-1 alpha-2 code", country)
+	}
+
+	var err error
+	if config.RequestTimeout, err = durationEnv("REQUEST_TIMEOUT", DefaultRequestTimeout); err != nil {
+		return nil, err
+	}
+	if config.ShutdownTimeout, err = durationEnv("SHUTDOWN_TIMEOUT", DefaultShutdownTimeout); err != nil {
+		return nil, err
+	}
+	if config.Agify, err = loadProvider("AGIFY", DefaultAgifyURL); err != nil {
+		return nil, err
+	}
+	if config.Genderize, err = loadProvider("GENDERIZE", DefaultGenderizeURL); err != nil {
+		return nil, err
+	}
+	if config.Nationalize, err = loadProvider("NATIONALIZE", DefaultNationalizeURL); err != nil {
+		return nil, err
+	}
+
+	if config.CircuitBreaker.FailureThreshold, err = intEnv("ENRICHMENT_BREAKER_FAILURE_THRESHOLD", DefaultBreakerFailureThreshold); err != nil {
+		return nil, err
+	}
+	if config.CircuitBreaker.OpenTimeout, err = durationEnv("ENRICHMENT_BREAKER_OPEN_TIMEOUT", DefaultBreakerOpenTimeout); err != nil {
+		return nil, err
+	}
+	if config.CircuitBreaker.HalfOpenRequests, err = intEnv("ENRICHMENT_BREAKER_HALF_OPEN_REQUESTS", DefaultBreakerHalfOpenRequests); err != nil {
+		return nil, err
+	}
+	if config.EnrichmentJobs.Workers, err = intEnv("ENRICHMENT_WORKERS", DefaultEnrichmentWorkers); err != nil {
+		return nil, err
+	}
+	if config.EnrichmentJobs.PollInterval, err = durationEnv("ENRICHMENT_POLL_INTERVAL", DefaultJobPollInterval); err != nil {
+		return nil, err
+	}
+	if config.EnrichmentJobs.Deadline, err = durationEnv("ENRICHMENT_DEADLINE", DefaultEnrichmentDeadline); err != nil {
+		return nil, err
+	}
+	if config.EnrichmentJobs.MaxAttempts, err = intEnv("ENRICHMENT_JOB_MAX_ATTEMPTS", DefaultJobMaxAttempts); err != nil {
+		return nil, err
+	}
+	if config.EnrichmentJobs.RetryDelay, err = durationEnv("ENRICHMENT_JOB_RETRY_DELAY", DefaultJobRetryDelay); err != nil {
+		return nil, err
+	}
+	if config.EnrichmentJobs.MaxRetryDelay, err = durationEnv("ENRICHMENT_JOB_MAX_RETRY_DELAY", DefaultJobMaxRetryDelay); err != nil {
+		return nil, err
+	}
+	if config.EnrichmentJobs.LockTimeout, err = durationEnv("ENRICHMENT_JOB_LOCK_TIMEOUT", DefaultJobLockTimeout); err != nil {
+		return nil, err
+	}
+	if config.EnrichmentJobs.BatchSize, err = intEnv("ENRICHMENT_JOB_BATCH_SIZE", DefaultJobBatchSize); err != nil {
+		return nil, err
+	}
+
+	config.EnrichmentDataset.Path = os.Getenv("ENRICHMENT_DATASET_PATH")
+	if config.EnrichmentDataset.ReloadInterval, err = durationEnv("ENRICHMENT_DATASET_RELOAD_INTERVAL", DefaultDatasetReloadInterval); err != nil {
+		return nil, err
+	}
+	if config.EnrichmentDataset.Fallback, err = boolEnv("ENRICHMENT_DATASET_FALLBACK", false); err != nil {
+		return nil, err
+	}
+
+	if config.EnrichmentCache.TTL, err = durationEnv("ENRICHMENT_CACHE_TTL", DefaultEnrichmentCacheTTL); err != nil {
+		return nil, err
+	}
+	if config.EnrichmentCache.Size, err = intEnv("ENRICHMENT_CACHE_SIZE", DefaultEnrichmentCacheSize); err != nil {
+		return nil, err
+	}
+
+	if config.Purge.Retention, err = durationEnv("PURGE_RETENTION", DefaultPurgeRetention); err != nil {
+		return nil, err
+	}
+	if config.Purge.Interval, err = durationEnv("PURGE_INTERVAL", DefaultPurgeInterval); err != nil {
+		return nil, err
+	}
+
+	config.Duplicates.Fields = splitList(os.Getenv("DUPLICATE_MATCH_FIELDS"))
+	if len(config.Duplicates.Fields) == 0 {
+		config.Duplicates.Fields = splitList(DefaultDuplicateMatchFields)
+	}
+	for _, field := range config.Duplicates.Fields {
+		if field != "name" && field != "surname" && field != "patronymic" {
+			return nil, fmt.Errorf("invalid DUPLICATE_MATCH_FIELDS: unknown field %q, expected name, surname or patronymic", field)
+		}
+	}
+	if !slices.Contains(config.Duplicates.Fields, "name") && !slices.Contains(config.Duplicates.Fields, "surname") {
+		// a missing patronymic matches any other, so patronymics alone would pair up almost everyone
+		return nil, fmt.Errorf("invalid DUPLICATE_MATCH_FIELDS: expected name or surname among the fields")
+	}
+	if config.Duplicates.MinSimilarity, err = floatEnv("DUPLICATE_MIN_SIMILARITY", DefaultDuplicateMinSimilarity); err != nil {
+		return nil, err
+	}
+	if s := config.Duplicates.MinSimilarity; s <= 0 || s > 1 {
+		return nil, fmt.Errorf("invalid DUPLICATE_MIN_SIMILARITY %v: expected a value in (0, 1]", s)
+	}
+
 	return config, nil
 }
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// loadProvider reads <PREFIX>_URL, <PREFIX>_TIMEOUT, <PREFIX>_API_KEY and the <PREFIX>_RETRY_* budget
+func loadProvider(prefix, defaultURL string) (ProviderConfig, error) {
+	provider := ProviderConfig{
+		BaseURL: strings.TrimRight(os.Getenv(prefix+"_URL"), "/"),
+		APIKey:  os.Getenv(prefix + "_API_KEY"),
+	}
+	if provider.BaseURL == "" {
+		provider.BaseURL = defaultURL
+	}
+
+	timeout, err := durationEnv(prefix+"_TIMEOUT", DefaultEnrichmentTimeout)
+	if err != nil {
+		return ProviderConfig{}, err
+	}
+	provider.Timeout = timeout
+
+	if provider.Retry.MaxAttempts, err = intEnv(prefix+"_RETRY_MAX_ATTEMPTS", DefaultRetryMaxAttempts); err != nil {
+		return ProviderConfig{}, err
+	}
+	if provider.Retry.MaxAttempts < 1 {
+		provider.Retry.MaxAttempts = 1
+	}
+	if provider.Retry.BaseDelay, err = durationEnv(prefix+"_RETRY_BASE_DELAY", DefaultRetryBaseDelay); err != nil {
+		return ProviderConfig{}, err
+	}
+	if provider.Retry.MaxDelay, err = durationEnv(prefix+"_RETRY_MAX_DELAY", DefaultRetryMaxDelay); err != nil {
+		return ProviderConfig{}, err
+	}
+	return provider, nil
+}
+
+func durationEnv(key string, defaultValue time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		logrus.Errorf("Invalid %s: %v", key, err)
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return d, nil
+}
+
+func intEnv(key string, defaultValue int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		logrus.Errorf("Invalid %s: %v", key, err)
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return n, nil
+}
+
+func boolEnv(key string, defaultValue bool) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		logrus.Errorf("Invalid %s: %v", key, err)
+		return false, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return b, nil
+}
+
+func floatEnv(key string, defaultValue float64) (float64, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		logrus.Errorf("Invalid %s: %v", key, err)
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return f, nil
+}
@@ -0,0 +1,15 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EnrichmentCacheEntry is a raw provider answer for a normalized name
+type EnrichmentCacheEntry struct {
+	Name      string          `json:"name" db:"name"`
+	Provider  string          `json:"provider" db:"provider"`
+	Payload   json.RawMessage `json:"payload" db:"payload"`
+	FetchedAt time.Time       `json:"fetched_at" db:"fetched_at"`
+	TTL       time.Duration   `json:"-" db:"ttl"`
+}
@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EnrichmentCall is one request to an enrichment provider, retries included, with its raw answer.
+// A batched call asks for several names at once and is linked to every person it was made for.
+type EnrichmentCall struct {
+	ID         int64           `json:"id" db:"id"`
+	Provider   string          `json:"provider" db:"provider"`
+	Names      []string        `json:"names" db:"names"`
+	CountryID  *string         `json:"country_id,omitempty" db:"country_id"`
+	PersonIDs  []int64         `json:"person_ids" db:"person_ids"`
+	StatusCode *int            `json:"status_code,omitempty" db:"status_code"`
+	LatencyMs  int64           `json:"latency_ms" db:"latency_ms"`
+	Response   json.RawMessage `json:"response,omitempty" db:"response" swaggertype:"object"`
+	Error      *string         `json:"error,omitempty" db:"error"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+}
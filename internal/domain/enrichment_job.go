@@ -0,0 +1,60 @@
+package domain
+
+import "time"
+
+const (
+	FieldAge         = "age"
+	FieldGender      = "gender"
+	FieldNationality = "nationality"
+)
+
+// EnrichableFields lists the person fields filled by enrichment providers
+var EnrichableFields = []string{FieldAge, FieldGender, FieldNationality}
+
+const (
+	JobQueued  = "queued"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+)
+
+const (
+	BatchEnqueuing = "enqueuing"
+	BatchRunning   = "running"
+	BatchDone      = "done"
+	BatchFailed    = "failed"
+)
+
+// EnrichmentJob asks the workers to run the given providers for a person.
+// Refresh jobs overwrite Fields; the others only fill fields that are still empty.
+// Fields set manually are skipped unless the job is forced.
+type EnrichmentJob struct {
+	ID        int64     `json:"id" db:"id"`
+	PersonID  int64     `json:"person_id" db:"person_id"`
+	Providers []string  `json:"providers" db:"providers"`
+	Fields    []string  `json:"fields" db:"fields"`
+	Refresh   bool      `json:"refresh" db:"refresh"`
+	Force     bool      `json:"force" db:"force"`
+	BatchID   *int64    `json:"batch_id,omitempty" db:"batch_id"`
+	Status    string    `json:"status" db:"status" enums:"queued,running,done,failed"`
+	Attempts  int       `json:"attempts" db:"attempts"`
+	LastError *string   `json:"last_error,omitempty" db:"last_error"`
+	RunAt     time.Time `json:"run_at" db:"run_at"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// EnrichmentBatch re-enriches every person matching Filters through one job per person
+type EnrichmentBatch struct {
+	ID        int64        `json:"id" db:"id"`
+	Filters   PersonFilter `json:"filters" db:"filters"`
+	Fields    []string     `json:"fields" db:"fields"`
+	Force     bool         `json:"force" db:"force"`
+	Status    string       `json:"status" db:"status" enums:"enqueuing,running,done,failed"`
+	Total     int          `json:"total" db:"total"`
+	Queued    int          `json:"queued"`
+	Running   int          `json:"running"`
+	Done      int          `json:"done"`
+	Failed    int          `json:"failed"`
+	Error     *string      `json:"error,omitempty" db:"error"`
+	CreatedAt time.Time    `json:"created_at" db:"created_at"`
+}
@@ -2,13 +2,56 @@ package domain
 
 import "time"
 
+const (
+	EnrichmentPending  = "pending"
+	EnrichmentPartial  = "partial"
+	EnrichmentComplete = "complete"
+	EnrichmentFailed   = "failed"
+)
+
+// Person is a stored person. The *Normalized fields hold the lower-case Latin forms of the names,
+// used as enrichment keys and for search. CountryID is the hint used to localize age and gender predictions,
+// AgeLocalization and GenderLocalization record the country each prediction came from (nil for global data).
+// GenderSource is the provider that set the gender, or "patronymic"/"surname" for morphology rules.
 type Person struct {
-	ID          int64     `json:"id" db:"id"`
-	Name        string    `json:"name" db:"name" binding:"required, min=1, max=50"`
-	Surname     string    `json:"surname" db:"surname" binding:"required, min=1, max=100"`
-	Patronymic  *string   `json:"patronymic,omitempty" db:"patronymic" binding:"omitempty, min=1, max=100"`
-	Age         int       `json:"age,omitempty" db:"age" binding:"omitempty, min=0, max=120"`
-	Gender      string    `json:"gender,omitempty" db:"gender" binding:"omitempty,oneof=male female other"`
-	Nationality string    `json:"nationality,omitempty" db:"nationality" binding:"omitempty,min=2,max=100"`
-	CreatedAt   time.Time `json:"createdAt" db:"created_at"`
+	ID                     int64                       `json:"id" db:"id"`
+	Name                   string                      `json:"name" db:"name" binding:"required, min=1, max=50"`
+	Surname                string                      `json:"surname" db:"surname" binding:"required, min=1, max=100"`
+	Patronymic             *string                     `json:"patronymic,omitempty" db:"patronymic" binding:"omitempty, min=1, max=100"`
+	NameNormalized         string                      `json:"name_normalized,omitempty" db:"name_normalized"`
+	SurnameNormalized      string                      `json:"surname_normalized,omitempty" db:"surname_normalized"`
+	PatronymicNormalized   *string                     `json:"patronymic_normalized,omitempty" db:"patronymic_normalized"`
+	CountryID              *string                     `json:"country_id,omitempty" db:"country_id"`
+	Age                    int                         `json:"age,omitempty" db:"age" binding:"omitempty, min=0, max=120"`
+	AgeLocalization        *string                     `json:"age_localization,omitempty" db:"age_localization"`
+	Gender                 string                      `json:"gender,omitempty" db:"gender" binding:"omitempty,oneof=male female other"`
+	GenderProbability      *float64                    `json:"gender_probability,omitempty" db:"gender_probability"`
+	GenderCount            *int                        `json:"gender_count,omitempty" db:"gender_count"`
+	GenderLocalization     *string                     `json:"gender_localization,omitempty" db:"gender_localization"`
+	GenderSource           *string                     `json:"gender_source,omitempty" db:"gender_source"`
+	Nationality            string                      `json:"nationality,omitempty" db:"nationality" binding:"omitempty,min=2,max=100"`
+	NationalityProbability *float64                    `json:"nationality_probability,omitempty" db:"nationality_probability"`
+	Nationalities          []CountryProbability        `json:"nationalities,omitempty"`
+	Provenance             map[string]*FieldProvenance `json:"provenance,omitempty"`
+	EnrichmentStatus       string                      `json:"enrichment_status" db:"enrichment_status" enums:"pending,partial,complete,failed"`
+	CreatedAt              time.Time                   `json:"createdAt" db:"created_at"`
+	// DeletedAt is set for deleted people, which are purged after the retention period
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+}
+
+// ProvenanceManual is the provenance source of values set through the API
+const ProvenanceManual = "manual"
+
+// FieldProvenance tells where an enrichable field's value came from. Keyed by field name on Person.
+type FieldProvenance struct {
+	// Source is the provider name, "patronymic"/"surname" for morphology rules or "manual"
+	Source     string    `json:"source" db:"source"`
+	Confidence *float64  `json:"confidence,omitempty" db:"confidence"`
+	FetchedAt  time.Time `json:"fetched_at" db:"fetched_at"`
+}
+
+// CountryProbability is one entry of the ranked nationality distribution, most likely first
+type CountryProbability struct {
+	CountryID   string  `json:"country_id" db:"country_id"`
+	Probability float64 `json:"probability" db:"probability"`
 }
@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// PersonFilter selects people for the list and bulk re-enrichment endpoints.
+// Nil fields do not restrict anything; the set ones must all hold.
+type PersonFilter struct {
+	Name                   *StringFilter `json:"name,omitempty"`
+	Surname                *StringFilter `json:"surname,omitempty"`
+	Patronymic             *StringFilter `json:"patronymic,omitempty"`
+	Age                    *IntFilter    `json:"age,omitempty"`
+	Gender                 *StringFilter `json:"gender,omitempty"`
+	Nationality            *StringFilter `json:"nationality,omitempty"`
+	CountryID              *StringFilter `json:"country_id,omitempty"`
+	EnrichmentStatus       *StringFilter `json:"enrichment_status,omitempty"`
+	GenderProbability      *FloatFilter  `json:"gender_probability,omitempty"`
+	NationalityProbability *FloatFilter  `json:"nationality_probability,omitempty"`
+	CreatedAt              *TimeFilter   `json:"created_at,omitempty"`
+	// IncludeDeleted also selects deleted people, which are left out by default
+	IncludeDeleted bool `json:"include_deleted,omitempty"`
+}
+
+// StringFilter restricts a text column. All set parts must hold; Not negates the filter as a whole,
+// so negated filters also match rows without a value.
+type StringFilter struct {
+	Eq *string `json:"eq,omitempty"`
+	// Contains matches case-insensitively anywhere in the value
+	Contains *string `json:"contains,omitempty"`
+	// Normalized is the normalized key of Contains. When set, the normalized form of the name may match instead.
+	Normalized *string  `json:"normalized,omitempty"`
+	In         []string `json:"in,omitempty"`
+	// Null selects rows without a value (true) or with one (false). Empty strings count as no value.
+	Null *bool `json:"null,omitempty"`
+	Not  bool  `json:"not,omitempty"`
+}
+
+// IntFilter restricts an integer column. Min and Max are inclusive; zero counts as no value.
+type IntFilter struct {
+	Eq   *int  `json:"eq,omitempty"`
+	Min  *int  `json:"min,omitempty"`
+	Max  *int  `json:"max,omitempty"`
+	In   []int `json:"in,omitempty"`
+	Null *bool `json:"null,omitempty"`
+	Not  bool  `json:"not,omitempty"`
+}
+
+// FloatFilter restricts a numeric column such as a probability. Min and Max are inclusive.
+type FloatFilter struct {
+	Min  *float64 `json:"min,omitempty"`
+	Max  *float64 `json:"max,omitempty"`
+	Null *bool    `json:"null,omitempty"`
+	Not  bool     `json:"not,omitempty"`
+}
+
+// TimeFilter restricts a timestamp column to [From, To)
+type TimeFilter struct {
+	From *time.Time `json:"from,omitempty"`
+	To   *time.Time `json:"to,omitempty"`
+	Not  bool       `json:"not,omitempty"`
+}
+
+// StringEq returns a filter matching value exactly
+func StringEq(value string) *StringFilter {
+	return &StringFilter{Eq: &value}
+}
+
+// StringContains returns a filter matching values that contain value, ignoring case
+func StringContains(value string) *StringFilter {
+	return &StringFilter{Contains: &value}
+}
+
+// Fields the people list can be sorted by
+const (
+	SortID          = "id"
+	SortName        = "name"
+	SortSurname     = "surname"
+	SortAge         = "age"
+	SortGender      = "gender"
+	SortNationality = "nationality"
+	SortCreatedAt   = "created_at"
+)
+
+var SortableFields = []string{SortID, SortName, SortSurname, SortAge, SortGender, SortNationality, SortCreatedAt}
+
+// SortOrder is one sort key of the people list
+type SortOrder struct {
+	Field string `json:"field"`
+	Desc  bool   `json:"desc,omitempty"`
+}
+
+// DefaultSort lists the newest people first
+var DefaultSort = []SortOrder{{Field: SortCreatedAt, Desc: true}}
+
+// ParseSort reads a comma-separated list of sortable fields, each optionally prefixed with "-"
+// for descending order, e.g. "surname,-age". An empty value gives DefaultSort.
+func ParseSort(value string) ([]SortOrder, error) {
+	if strings.TrimSpace(value) == "" {
+		return DefaultSort, nil
+	}
+	var orders []SortOrder
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		order := SortOrder{Field: strings.TrimPrefix(item, "-"), Desc: strings.HasPrefix(item, "-")}
+		if !slices.Contains(SortableFields, order.Field) {
+			return nil, fmt.Errorf("unknown sort field %q, expected one of %s", order.Field, strings.Join(SortableFields, ", "))
+		}
+		if slices.ContainsFunc(orders, func(o SortOrder) bool { return o.Field == order.Field }) {
+			return nil, fmt.Errorf("duplicate sort field %q", order.Field)
+		}
+		orders = append(orders, order)
+	}
+	return orders, nil
+}
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Names duplicates can be matched on, compared by their normalized forms
+const (
+	MatchName       = "name"
+	MatchSurname    = "surname"
+	MatchPatronymic = "patronymic"
+)
+
+var DuplicateMatchFields = []string{MatchName, MatchSurname, MatchPatronymic}
+
+// DuplicateMatch selects pairs of people that look like the same person
+type DuplicateMatch struct {
+	// Fields are the names that must match, including name or surname. Patronymics only count when both people have one.
+	Fields []string
+	// MinSimilarity is the trigram similarity each field needs; 1 requires equal normalized forms
+	MinSimilarity float64
+	Limit         int
+	Offset        int
+}
+
+// DuplicatePair is two people that look like the same person. Person is the older one, the suggested survivor.
+type DuplicatePair struct {
+	Person    *Person `json:"person"`
+	Duplicate *Person `json:"duplicate"`
+	// Similarity is the lowest similarity of the matched fields, 1 when they are equal
+	Similarity float64 `json:"similarity"`
+}
+
+// Person fields a merge can take over from the duplicate, besides the enrichable ones
+const (
+	FieldPatronymic = "patronymic"
+	FieldCountryID  = "country_id"
+)
+
+// PersonMerge records that a duplicate was merged into a survivor. Lookups of MergedID lead to SurvivorID until the merge is undone.
+type PersonMerge struct {
+	ID         int64 `json:"id" db:"id"`
+	SurvivorID int64 `json:"survivor_id" db:"survivor_id"`
+	MergedID   int64 `json:"merged_id" db:"merged_id"`
+	// Fields lists the survivor's fields taken over from the duplicate
+	Fields []string `json:"fields" db:"fields"`
+	// Merged is the duplicate as it was before the merge
+	Merged   *Person   `json:"merged" db:"merged"`
+	MergedAt time.Time `json:"merged_at" db:"merged_at"`
+	// UndoneAt is set once the duplicate was restored; the merge no longer leads to the survivor
+	UndoneAt *time.Time `json:"undone_at,omitempty" db:"undone_at"`
+}
+
+// MergeFields returns the fields of survivor to take over from duplicate: the ones survivor lacks, and the
+// enrichable ones set manually on duplicate but not on survivor, since a value someone entered beats a prediction.
+func MergeFields(survivor, duplicate *Person) []string {
+	var fields []string
+	if survivor.Patronymic == nil && duplicate.Patronymic != nil {
+		fields = append(fields, FieldPatronymic)
+	}
+	if survivor.CountryID == nil && duplicate.CountryID != nil {
+		fields = append(fields, FieldCountryID)
+	}
+	values := map[string][2]bool{
+		FieldAge:         {survivor.Age != 0, duplicate.Age != 0},
+		FieldGender:      {survivor.Gender != "", duplicate.Gender != ""},
+		FieldNationality: {survivor.Nationality != "", duplicate.Nationality != ""},
+	}
+	manual := func(person *Person, field string) bool {
+		provenance := person.Provenance[field]
+		return provenance != nil && provenance.Source == ProvenanceManual
+	}
+	for _, field := range EnrichableFields {
+		has := values[field]
+		if !has[1] {
+			continue
+		}
+		if !has[0] || (manual(duplicate, field) && !manual(survivor, field)) {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// PersonMergedError is returned for people that were merged into another one
+type PersonMergedError struct {
+	ID         int64
+	SurvivorID int64
+}
+
+func (e *PersonMergedError) Error() string {
+	return fmt.Sprintf("person %d was merged into %d", e.ID, e.SurvivorID)
+}
@@ -0,0 +1,191 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// How the total number of matching people is counted
+const (
+	// CountExact runs COUNT(*) with the list's filters
+	CountExact = "exact"
+	// CountEstimated takes the planner's row estimate, which is cheap but may be off
+	CountEstimated = "estimated"
+	// CountNone skips counting
+	CountNone = "none"
+)
+
+var CountModes = []string{CountExact, CountEstimated, CountNone}
+
+// PersonQuery selects one page of the people list. With a Cursor the page starts next to the
+// cursor's row and Offset is ignored.
+type PersonQuery struct {
+	Filter PersonFilter
+	Sort   []SortOrder
+	Limit  int
+	Offset int
+	Cursor *Cursor
+	// Count is one of CountModes; empty means CountExact
+	Count string
+}
+
+// PersonPage is one page of the people list. Next and Prev point at the neighbouring pages
+// and are nil at the ends of the list. Total is nil when counting was skipped; CountMode tells
+// how it was counted, which can differ from the requested mode.
+type PersonPage struct {
+	People    []*Person
+	Total     *int
+	CountMode string
+	Next      *Cursor
+	Prev      *Cursor
+}
+
+// Cursor marks a position in the people list by the sort key values of the row next to it.
+// Clients only see it encoded, as an opaque string.
+type Cursor struct {
+	// Sort is the order the cursor was issued for, in the syntax of ParseSort
+	Sort string `json:"s"`
+	// Values are the row's values of SortKeys(Sort), formatted with SortValue
+	Values []string `json:"v"`
+	// Backward cursors select the rows before the row instead of after it
+	Backward bool `json:"b,omitempty"`
+}
+
+// SortKeys returns sort with id appended as tiebreaker, in the direction of the last key,
+// unless sort already includes it. Rows are ordered by these keys, so their order is stable.
+func SortKeys(sort []SortOrder) []SortOrder {
+	if len(sort) == 0 {
+		sort = DefaultSort
+	}
+	if slices.ContainsFunc(sort, func(o SortOrder) bool { return o.Field == SortID }) {
+		return sort
+	}
+	keys := slices.Clone(sort)
+	return append(keys, SortOrder{Field: SortID, Desc: sort[len(sort)-1].Desc})
+}
+
+// FormatSort is the inverse of ParseSort
+func FormatSort(sort []SortOrder) string {
+	items := make([]string, len(sort))
+	for i, order := range sort {
+		items[i] = order.Field
+		if order.Desc {
+			items[i] = "-" + order.Field
+		}
+	}
+	return strings.Join(items, ",")
+}
+
+// SortValue formats the value of a sortable field of person for a cursor.
+// Missing values are formatted as zero or the empty string, the way they are sorted.
+func SortValue(person *Person, field string) string {
+	switch field {
+	case SortID:
+		return strconv.FormatInt(person.ID, 10)
+	case SortName:
+		return person.Name
+	case SortSurname:
+		return person.Surname
+	case SortAge:
+		return strconv.Itoa(person.Age)
+	case SortGender:
+		return person.Gender
+	case SortNationality:
+		return person.Nationality
+	case SortCreatedAt:
+		return person.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+	return ""
+}
+
+// NewCursor returns the cursor of the rows after person, or before it when backward is set
+func NewCursor(sort []SortOrder, person *Person, backward bool) *Cursor {
+	keys := SortKeys(sort)
+	values := make([]string, len(keys))
+	for i, key := range keys {
+		values[i] = SortValue(person, key.Field)
+	}
+	return &Cursor{Sort: FormatSort(sort), Values: values, Backward: backward}
+}
+
+// KeyValues returns the cursor's values typed like the sort keys they belong to
+func (c *Cursor) KeyValues() ([]interface{}, error) {
+	sort, err := ParseSort(c.Sort)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	keys := SortKeys(sort)
+	if len(keys) != len(c.Values) {
+		return nil, fmt.Errorf("%w: expected %d values, got %d", ErrInvalidCursor, len(keys), len(c.Values))
+	}
+	values := make([]interface{}, len(keys))
+	for i, key := range keys {
+		raw := c.Values[i]
+		switch key.Field {
+		case SortID:
+			values[i], err = strconv.ParseInt(raw, 10, 64)
+		case SortAge:
+			values[i], err = strconv.Atoi(raw)
+		case SortCreatedAt:
+			values[i], err = time.Parse(time.RFC3339Nano, raw)
+		default:
+			values[i] = raw
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: bad %s value", ErrInvalidCursor, key.Field)
+		}
+	}
+	return values, nil
+}
+
+// Encode returns the opaque form of the cursor handed out to clients
+func (c *Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses and validates a cursor produced by Encode
+func DecodeCursor(value string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	cursor := &Cursor{}
+	if err := json.Unmarshal(data, cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if _, err := cursor.KeyValues(); err != nil {
+		return nil, err
+	}
+	return cursor, nil
+}
+
+// DefaultSearchSimilarity is the word similarity a fuzzy search hit needs by default.
+// It lets one or two typos through in a name ("Dmitry" finds "Dmitriy").
+const DefaultSearchSimilarity = 0.4
+
+// PersonSearch is a fuzzy search over the names of people
+type PersonSearch struct {
+	Query string
+	// Key is the normalized form of Query, matched against the normalized names
+	Key string
+	// MinSimilarity is the word similarity (0..1) a hit needs unless it matches all words exactly
+	MinSimilarity float64
+	Filter        PersonFilter
+	Limit         int
+}
+
+// PersonSearchResult is a person found by a search, with how well the names matched
+type PersonSearchResult struct {
+	*Person
+	// Similarity is the trigram word similarity of the query to the person's names, 1 for a full match
+	Similarity float64 `json:"similarity"`
+}
@@ -0,0 +1,167 @@
+package domain
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSortKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		sort []SortOrder
+		want []SortOrder
+	}{
+		{
+			name: "default",
+			want: []SortOrder{{Field: SortCreatedAt, Desc: true}, {Field: SortID, Desc: true}},
+		},
+		{
+			name: "id follows the last key",
+			sort: []SortOrder{{Field: SortSurname, Desc: true}, {Field: SortAge}},
+			want: []SortOrder{{Field: SortSurname, Desc: true}, {Field: SortAge}, {Field: SortID}},
+		},
+		{
+			name: "id already included",
+			sort: []SortOrder{{Field: SortID, Desc: true}, {Field: SortName}},
+			want: []SortOrder{{Field: SortID, Desc: true}, {Field: SortName}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SortKeys(tt.sort); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	sort := []SortOrder{{Field: SortName}}
+	SortKeys(sort)
+	if len(sort) != 1 {
+		t.Error("SortKeys modified its argument")
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	person := &Person{
+		ID:          42,
+		Name:        "Анна-Мария",
+		Surname:     "O'Brien, \"Jr\"",
+		Age:         39,
+		Gender:      "female",
+		Nationality: "RU",
+		CreatedAt:   time.Date(2024, 5, 1, 12, 30, 15, 123456789, moscow),
+	}
+
+	tests := []struct {
+		sort     string
+		backward bool
+		want     []interface{}
+	}{
+		{sort: "", want: []interface{}{person.CreatedAt, int64(42)}},
+		{sort: "-created_at", backward: true, want: []interface{}{person.CreatedAt, int64(42)}},
+		{sort: "surname,-name", want: []interface{}{person.Surname, person.Name, int64(42)}},
+		{sort: "-age,gender,nationality", want: []interface{}{39, "female", "RU", int64(42)}},
+		{sort: "id", want: []interface{}{int64(42)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			sort, err := ParseSort(tt.sort)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cursor := NewCursor(sort, person, tt.backward)
+			decoded, err := DecodeCursor(cursor.Encode())
+			if err != nil {
+				t.Fatalf("DecodeCursor: %v", err)
+			}
+			if !reflect.DeepEqual(decoded, cursor) {
+				t.Fatalf("decoded %+v, want %+v", decoded, cursor)
+			}
+			if decoded.Backward != tt.backward {
+				t.Errorf("backward = %v, want %v", decoded.Backward, tt.backward)
+			}
+
+			values, err := decoded.KeyValues()
+			if err != nil {
+				t.Fatalf("KeyValues: %v", err)
+			}
+			if len(values) != len(tt.want) {
+				t.Fatalf("got %d values, want %d", len(values), len(tt.want))
+			}
+			for i, want := range tt.want {
+				if wantTime, ok := want.(time.Time); ok {
+					// created_at keeps its nanoseconds and is compared as an instant
+					if got, ok := values[i].(time.Time); !ok || !got.Equal(wantTime) {
+						t.Errorf("value %d = %v, want %v", i, values[i], want)
+					}
+					continue
+				}
+				if values[i] != want {
+					t.Errorf("value %d = %#v, want %#v", i, values[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestCursorMissingValues(t *testing.T) {
+	// Unenriched people have no age, gender or nationality; they sort as 0 and ''
+	person := &Person{ID: 7, Name: "Ivan", Surname: "Ivanov"}
+	sort, _ := ParseSort("age,-gender,nationality")
+	cursor := NewCursor(sort, person, false)
+	if want := []string{"0", "", "", "7"}; !reflect.DeepEqual(cursor.Values, want) {
+		t.Fatalf("values = %q, want %q", cursor.Values, want)
+	}
+
+	decoded, err := DecodeCursor(cursor.Encode())
+	if err != nil {
+		t.Fatalf("DecodeCursor: %v", err)
+	}
+	values, err := decoded.KeyValues()
+	if err != nil {
+		t.Fatalf("KeyValues: %v", err)
+	}
+	if want := []interface{}{0, "", "", int64(7)}; !reflect.DeepEqual(values, want) {
+		t.Errorf("values = %#v, want %#v", values, want)
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	encode := func(json string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(json))
+	}
+	valid := NewCursor(DefaultSort, &Person{ID: 1, CreatedAt: time.Now()}, false).Encode()
+
+	tests := map[string]string{
+		"empty":               "",
+		"not base64":          "%%%",
+		"padded base64":       valid + "==",
+		"truncated":           valid[:len(valid)-3],
+		"not JSON":            encode("cursor"),
+		"wrong JSON type":     encode(`[1, 2]`),
+		"values not strings":  encode(`{"s": "id", "v": [1]}`),
+		"unknown sort field":  encode(`{"s": "height", "v": ["180", "1"]}`),
+		"too few values":      encode(`{"s": "name", "v": ["Ivan"]}`),
+		"too many values":     encode(`{"s": "id", "v": ["1", "2"]}`),
+		"no values":           encode(`{"s": "-created_at"}`),
+		"bad id":              encode(`{"s": "id", "v": ["1 OR 1=1"]}`),
+		"bad age":             encode(`{"s": "age", "v": ["old", "1"]}`),
+		"bad created_at":      encode(`{"s": "-created_at", "v": ["yesterday", "1"]}`),
+		"sort of another key": encode(`{"s": "age", "v": ["2024-05-01T12:30:15Z", "1"]}`),
+	}
+
+	for name, value := range tests {
+		t.Run(name, func(t *testing.T) {
+			cursor, err := DecodeCursor(value)
+			if !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("DecodeCursor = %+v, %v, want %v", cursor, err, ErrInvalidCursor)
+			}
+		})
+	}
+}
@@ -1,9 +1,85 @@
 package domain
 
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+)
+
 type CreatePersonRequest struct {
 	Name       string `json:"name" binding:"required"`
 	Surname    string `json:"surname" binding:"required"`
 	Patronymic string `json:"patronymic,omitempty"`
+	// CountryID localizes the age and gender predictions, e.g. "RU"; the service default is used when empty
+	CountryID string `json:"country_id,omitempty" binding:"omitempty,iso3166_1_alpha2" example:"RU"`
+	// Age, Gender and Nationality are known values; they are stored as set manually and never looked up
+	Age         int    `json:"age,omitempty" binding:"omitempty,min=0,max=120"`
+	Gender      string `json:"gender,omitempty" binding:"omitempty,oneof=male female other"`
+	Nationality string `json:"nationality,omitempty" binding:"omitempty,iso3166_1_alpha2" example:"RU"`
+	// Enrich is "all" (the default), "none" or a list of the fields to look up, e.g. ["age"]
+	Enrich EnrichSelection `json:"enrich,omitempty" swaggertype:"string" example:"all"`
+}
+
+// EnrichSelection picks the fields enriched after a person is created.
+// nil selects every enrichable field, an empty selection none.
+type EnrichSelection []string
+
+const (
+	EnrichAll  = "all"
+	EnrichNone = "none"
+)
+
+func (s *EnrichSelection) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var option string
+	if err := json.Unmarshal(data, &option); err == nil {
+		switch option {
+		case EnrichAll:
+			*s = nil
+		case EnrichNone:
+			*s = EnrichSelection{}
+		default:
+			return fmt.Errorf("invalid enrich option %q, expected %q, %q or a list of fields", option, EnrichAll, EnrichNone)
+		}
+		return nil
+	}
+
+	var fields []string
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("invalid enrich option: %w", err)
+	}
+	selection := EnrichSelection{}
+	for _, field := range fields {
+		if !slices.Contains(EnrichableFields, field) {
+			return fmt.Errorf("invalid enrich field %q", field)
+		}
+		if !slices.Contains(selection, field) {
+			selection = append(selection, field)
+		}
+	}
+	*s = selection
+	return nil
+}
+
+// Fields returns the selected fields
+func (s EnrichSelection) Fields() []string {
+	if s == nil {
+		return EnrichableFields
+	}
+	return s
+}
+
+type BulkCreatePeopleRequest struct {
+	People []CreatePersonRequest `json:"people" binding:"required,min=1,max=10000,dive"`
+}
+
+type BulkCreatePeopleResponse struct {
+	// IDs of the created people, in request order
+	IDs   []int64 `json:"ids"`
+	Count int     `json:"count"`
 }
 
 type UpdatePersonRequest struct {
@@ -24,8 +100,60 @@ type PersonListResponse struct {
 	Meta PaginationMeta `json:"meta"`
 }
 
+type PersonSearchResponse struct {
+	Data  []*PersonSearchResult `json:"data"`
+	Count int                   `json:"count"`
+}
+
+type DuplicatesResponse struct {
+	Data  []*DuplicatePair `json:"data"`
+	Count int              `json:"count"`
+}
+
+// MergePersonRequest names the duplicate to merge into the person of the URL
+type MergePersonRequest struct {
+	DuplicateID int64 `json:"duplicate_id" binding:"required,min=1"`
+}
+
+type MergePersonResponse struct {
+	Person *Person      `json:"person"`
+	Merge  *PersonMerge `json:"merge"`
+}
+
+// PaginationMeta describes a page of a list. The cursors are set when there is a next or previous page.
 type PaginationMeta struct {
-	Page       int `json:"page"`
-	PageSize   int `json:"page_size"`
-	TotalItems int `json:"total_items"`
+	// Page is always set for page based pagination and left out for cursor based pagination
+	Page     *int `json:"page,omitempty"`
+	PageSize int  `json:"page_size"`
+	// TotalItems is left out only when count=none was requested and is approximate when CountMode is "estimated"
+	TotalItems *int   `json:"total_items,omitempty"`
+	CountMode  string `json:"count_mode" enums:"exact,estimated,none"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+type EnrichmentCacheStats struct {
+	MemoryHits int64 `json:"memory_hits"`
+	StoreHits  int64 `json:"store_hits"`
+	Misses     int64 `json:"misses"`
+	Entries    int   `json:"entries"`
+}
+
+type PurgeEnrichmentCacheResponse struct {
+	// Purged counts the entries dropped from memory and deleted from the database; an entry held in both counts twice
+	Purged int64 `json:"purged"`
+}
+
+type EnrichmentProviderStatus struct {
+	Provider            string     `json:"provider"`
+	State               string     `json:"state" enums:"closed,open,half-open"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+}
+
+type EnrichRequest struct {
+	// Fields to refresh; all enrichable fields when empty
+	Fields []string `json:"fields,omitempty" binding:"omitempty,dive,oneof=age gender nationality"`
+	// Force also refreshes fields that were set manually
+	Force bool `json:"force,omitempty"`
 }
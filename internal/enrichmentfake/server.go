@@ -0,0 +1,175 @@
+// Package enrichmentfake serves deterministic agify/genderize/nationalize compatible responses,
+// so the service can run and be tested without access to the real APIs.
+package enrichmentfake
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"net/http"
+	"net/http/httptest"
+	"person-service/internal/config"
+	"strings"
+)
+
+const (
+	AgifyPath       = "/agify"
+	GenderizePath   = "/genderize"
+	NationalizePath = "/nationalize"
+
+	maxBatchNames = 10
+)
+
+type knownName struct {
+	age         int
+	gender      string
+	nationality string
+}
+
+// knownNames pins the answers for names used in docs and examples
+var knownNames = map[string]knownName{
+	"dmitriy": {age: 43, gender: "male", nationality: "UA"},
+	"anna":    {age: 39, gender: "female", nationality: "RU"},
+	"ivan":    {age: 47, gender: "male", nationality: "RU"},
+}
+
+var (
+	genders   = []string{"male", "female"}
+	countries = []string{"RU", "UA", "BY", "KZ", "PL"}
+)
+
+func NewHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(AgifyPath+"/", agify)
+	mux.HandleFunc(GenderizePath+"/", genderize)
+	mux.HandleFunc(NationalizePath+"/", nationalize)
+	return mux
+}
+
+// NewServer starts the fake on a random local port. The caller must Close it.
+func NewServer() *httptest.Server {
+	return httptest.NewServer(NewHandler())
+}
+
+// Configure points all providers of cfg at a fake served from baseURL.
+func Configure(cfg *config.Config, baseURL string) {
+	baseURL = strings.TrimRight(baseURL, "/")
+	cfg.Agify.BaseURL = baseURL + AgifyPath
+	cfg.Genderize.BaseURL = baseURL + GenderizePath
+	cfg.Nationalize.BaseURL = baseURL + NationalizePath
+}
+
+func agify(w http.ResponseWriter, r *http.Request) {
+	respond(w, r, func(name, countryID string) map[string]interface{} {
+		return localized(countryID, map[string]interface{}{
+			"count": count(name),
+			"name":  name,
+			"age":   localAge(lookup(name).age, countryID),
+		})
+	})
+}
+
+func genderize(w http.ResponseWriter, r *http.Request) {
+	respond(w, r, func(name, countryID string) map[string]interface{} {
+		return localized(countryID, map[string]interface{}{
+			"count":       count(name),
+			"name":        name,
+			"gender":      lookup(name).gender,
+			"probability": 0.98,
+		})
+	})
+}
+
+func nationalize(w http.ResponseWriter, r *http.Request) {
+	respond(w, r, func(name, _ string) map[string]interface{} {
+		return map[string]interface{}{
+			"count":   count(name),
+			"name":    name,
+			"country": countryDistribution(lookup(name).nationality),
+		}
+	})
+}
+
+// respond answers a single ?name= with an object and a batch of ?name[]= with an array, like the real APIs
+func respond(w http.ResponseWriter, r *http.Request, answer func(name, countryID string) map[string]interface{}) {
+	query := r.URL.Query()
+	countryID := strings.ToUpper(query.Get("country_id"))
+	names, batch := query["name[]"]
+	if !batch {
+		writeJSON(w, answer(query.Get("name"), countryID))
+		return
+	}
+	if len(names) > maxBatchNames {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		writeJSON(w, map[string]interface{}{"error": "Invalid 'name[]' parameter"})
+		return
+	}
+	answers := make([]map[string]interface{}, 0, len(names))
+	for _, name := range names {
+		answers = append(answers, answer(name, countryID))
+	}
+	writeJSON(w, answers)
+}
+
+// localized echoes the country_id of a localized answer, like the real APIs
+func localized(countryID string, answer map[string]interface{}) map[string]interface{} {
+	if countryID != "" {
+		answer["country_id"] = countryID
+	}
+	return answer
+}
+
+// localAge shifts the global age by a few years per country, so localized answers are distinguishable
+func localAge(age int, countryID string) int {
+	if countryID == "" {
+		return age
+	}
+	return age + int(hash(countryID)%7) - 3
+}
+
+// countryDistribution ranks top first, followed by two runners-up
+func countryDistribution(top string) []map[string]interface{} {
+	distribution := []map[string]interface{}{
+		{"country_id": top, "probability": 0.42},
+	}
+	probabilities := []float64{0.17, 0.08}
+	for _, country := range countries {
+		if len(distribution) > len(probabilities) {
+			break
+		}
+		if country != top {
+			distribution = append(distribution, map[string]interface{}{
+				"country_id":  country,
+				"probability": probabilities[len(distribution)-1],
+			})
+		}
+	}
+	return distribution
+}
+
+func lookup(name string) knownName {
+	key := strings.ToLower(strings.TrimSpace(name))
+	if known, ok := knownNames[key]; ok {
+		return known
+	}
+	h := hash(key)
+	return knownName{
+		age:         18 + int(h%60),
+		gender:      genders[h%uint32(len(genders))],
+		nationality: countries[h%uint32(len(countries))],
+	}
+}
+
+func count(name string) int {
+	return 100 + int(hash(strings.ToLower(name))%10000)
+}
+
+func hash(s string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(s))
+	return h.Sum32()
+}
+
+func writeJSON(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
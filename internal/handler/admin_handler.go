@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"os"
+	"person-service/internal/domain"
+	"person-service/internal/service"
+)
+
+type AdminHandler struct {
+	cache service.EnrichmentCacheInterface
+	log   *logrus.Logger
+}
+
+func NewAdminHandler(cache service.EnrichmentCacheInterface, log *logrus.Logger) *AdminHandler {
+	if log == nil {
+		log = logrus.New()
+		log.SetFormatter(&logrus.JSONFormatter{})
+		log.SetOutput(os.Stdout)
+		log.SetLevel(logrus.DebugLevel)
+	}
+	return &AdminHandler{
+		cache: cache,
+		log:   log,
+	}
+}
+
+// GetEnrichmentCacheStats returns enrichment cache counters
+// @Summary Get enrichment cache statistics
+// @Description Returns hit/miss counters of the enrichment cache since the service started
+// @Tags admin
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Success 200 {object} domain.EnrichmentCacheStats
+// @Failure 401 {object} domain.ErrorResponse "Invalid admin token"
+// @Failure 403 {object} domain.ErrorResponse "Admin API is disabled"
+// @Router /admin/enrichment-cache [get]
+func (h *AdminHandler) GetEnrichmentCacheStats(c *gin.Context) {
+	c.JSON(http.StatusOK, h.cache.Stats())
+}
+
+// PurgeEnrichmentCache deletes cached provider answers
+// @Summary Purge the enrichment cache
+// @Description Deletes cached provider answers, optionally only for one provider and/or name
+// @Tags admin
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param provider query string false "Provider name, e.g. agify"
+// @Param name query string false "Person name, purged together with its country-specific answers"
+// @Success 200 {object} domain.PurgeEnrichmentCacheResponse
+// @Failure 401 {object} domain.ErrorResponse "Invalid admin token"
+// @Failure 403 {object} domain.ErrorResponse "Admin API is disabled"
+// @Failure 500 {object} domain.ErrorResponse "Internal server error"
+// @Router /admin/enrichment-cache [delete]
+func (h *AdminHandler) PurgeEnrichmentCache(c *gin.Context) {
+	provider := c.Query("provider")
+	name := c.Query("name")
+
+	purged, err := h.cache.Purge(c.Request.Context(), provider, name)
+	if err != nil {
+		h.log.WithError(err).Error("Failed to purge enrichment cache")
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Error: "Failed to purge enrichment cache"})
+		return
+	}
+
+	h.log.WithFields(logrus.Fields{
+		"provider": provider,
+		"name":     name,
+		"purged":   purged,
+	}).Info("Enrichment cache purged successfully")
+	c.JSON(http.StatusOK, domain.PurgeEnrichmentCacheResponse{Purged: purged})
+}
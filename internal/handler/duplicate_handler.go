@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"os"
+	"person-service/internal/domain"
+	"person-service/internal/repository"
+	"person-service/internal/service"
+	"slices"
+	"strconv"
+)
+
+type DuplicateHandler struct {
+	service service.DuplicateServiceInterface
+	log     *logrus.Logger
+}
+
+func NewDuplicateHandler(service service.DuplicateServiceInterface, log *logrus.Logger) *DuplicateHandler {
+	if log == nil {
+		log = logrus.New()
+		log.SetFormatter(&logrus.JSONFormatter{})
+		log.SetOutput(os.Stdout)
+		log.SetLevel(logrus.DebugLevel)
+	}
+	return &DuplicateHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+// FindDuplicates lists people that look like the same person
+// @Summary Find duplicate persons
+// @Description Returns pairs of people whose normalized names match, most similar first. The older person of a pair
+// @Description is the suggested survivor for POST /person/{id}/merge. Patronymics only count when both people have one.
+// @Description Without parameters the matching configured by DUPLICATE_MATCH_FIELDS and DUPLICATE_MIN_SIMILARITY is used.
+// @Tags duplicates
+// @Produce json
+// @Param fields query string false "Comma-separated names to match: name, surname, patronymic; name or surname is required"
+// @Param min_similarity query number false "Trigram similarity (0..1) each field needs, 1 for equal names"
+// @Param limit query int false "Maximum number of pairs" default(10)
+// @Param offset query int false "Number of pairs to skip" default(0)
+// @Success 200 {object} domain.DuplicatesResponse
+// @Failure 400 {object} domain.ErrorResponse "Invalid query parameters"
+// @Failure 500 {object} domain.ErrorResponse "Internal server error"
+// @Router /people/duplicates [get]
+func (h *DuplicateHandler) FindDuplicates(c *gin.Context) {
+	var match domain.DuplicateMatch
+	if value := c.Query("fields"); value != "" {
+		match.Fields = splitList(value)
+		for _, field := range match.Fields {
+			if !slices.Contains(domain.DuplicateMatchFields, field) {
+				h.log.WithField("fields", value).Debug("Invalid fields parameter")
+				c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: "Invalid fields value"})
+				return
+			}
+		}
+		if !slices.Contains(match.Fields, domain.MatchName) && !slices.Contains(match.Fields, domain.MatchSurname) {
+			h.log.WithField("fields", value).Debug("Fields parameter without name or surname")
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: "Fields must include name or surname"})
+			return
+		}
+	}
+	if value := c.Query("min_similarity"); value != "" {
+		similarity, err := strconv.ParseFloat(value, 64)
+		if err != nil || similarity <= 0 || similarity > 1 {
+			h.log.WithField("min_similarity", value).Debug("Invalid min_similarity parameter")
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: "Invalid min_similarity value"})
+			return
+		}
+		match.MinSimilarity = similarity
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(paginationDefaultPageSize)))
+	if err != nil || limit < 1 {
+		limit = paginationDefaultPageSize
+	}
+	if limit > paginationMaxPageSize {
+		limit = paginationMaxPageSize
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	match.Limit, match.Offset = limit, offset
+
+	pairs, err := h.service.FindDuplicates(c.Request.Context(), match)
+	if err != nil {
+		h.log.WithError(err).Error("Failed to find duplicates")
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Error: "Failed to find duplicates"})
+		return
+	}
+	if pairs == nil {
+		pairs = []*domain.DuplicatePair{}
+	}
+	h.log.WithField("count", len(pairs)).Info("Duplicates found")
+	c.JSON(http.StatusOK, domain.DuplicatesResponse{Data: pairs, Count: len(pairs)})
+}
+
+// Merge merges a duplicate into a person
+// @Summary Merge a duplicate into a person
+// @Description Merges the duplicate into the person of the URL, the survivor. The survivor takes over the duplicate's
+// @Description patronymic, country, age, gender and nationality where it has none, and manually set values over predicted ones.
+// @Description The duplicate is deleted until purged, and restoring it undoes the merge; the merge is recorded and
+// @Description GET /person/{duplicate_id} redirects to the survivor.
+// @Tags duplicates
+// @Accept json
+// @Produce json
+// @Param id path int true "Survivor ID"
+// @Param merge body domain.MergePersonRequest true "Duplicate to merge"
+// @Success 200 {object} domain.MergePersonResponse
+// @Failure 400 {object} domain.ErrorResponse "Invalid request body or ID"
+// @Failure 404 {object} domain.ErrorResponse "Person not found"
+// @Failure 500 {object} domain.ErrorResponse "Internal server error"
+// @Router /person/{id}/merge [post]
+func (h *DuplicateHandler) Merge(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log.WithError(err).Debug("Invalid ID parameter")
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	var request domain.MergePersonRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.log.WithError(err).Debug("Failed to bind request")
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+	if request.DuplicateID == id {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: "A person cannot be merged into itself"})
+		return
+	}
+
+	person, merge, err := h.service.Merge(c.Request.Context(), id, request.DuplicateID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, domain.ErrorResponse{Error: "Person not found"})
+			return
+		}
+		h.log.WithError(err).Error("Failed to merge people")
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Error: "Failed to merge people"})
+		return
+	}
+
+	h.log.WithFields(logrus.Fields{
+		"id":           id,
+		"duplicate_id": request.DuplicateID,
+		"fields":       merge.Fields,
+	}).Info("People merged successfully")
+	c.JSON(http.StatusOK, domain.MergePersonResponse{Person: person, Merge: merge})
+}
+
+// GetMerges returns the duplicates merged into a person
+// @Summary Get the merges into a person
+// @Description Returns the audit trail of duplicates merged into the person, or into people merged into it, newest
+// @Description first, each with the fields taken over and the duplicate as it was before the merge. Merges undone by
+// @Description restoring the duplicate stay in the trail with undone_at set.
+// @Tags duplicates
+// @Produce json
+// @Param id path int true "Person ID"
+// @Success 200 {array} domain.PersonMerge
+// @Failure 400 {object} domain.ErrorResponse "Invalid ID format"
+// @Failure 404 {object} domain.ErrorResponse "Person not found"
+// @Failure 500 {object} domain.ErrorResponse "Internal server error"
+// @Router /person/{id}/merges [get]
+func (h *DuplicateHandler) GetMerges(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log.WithError(err).Debug("Invalid ID parameter")
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	merges, err := h.service.GetMerges(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, domain.ErrorResponse{Error: "Person not found"})
+			return
+		}
+		h.log.WithError(err).Error("Failed to get merges")
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Error: "Failed to get merges"})
+		return
+	}
+	c.JSON(http.StatusOK, merges)
+}
@@ -0,0 +1,272 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+	"os"
+	"person-service/internal/domain"
+	"person-service/internal/repository"
+	"person-service/internal/service"
+	"strconv"
+)
+
+const (
+	historyDefaultLimit = 50
+	historyMaxLimit     = 500
+)
+
+type EnrichmentStatusProvider interface {
+	Status() []domain.EnrichmentProviderStatus
+}
+
+type EnrichmentHandler struct {
+	service  service.EnrichmentServiceInterface
+	breakers EnrichmentStatusProvider
+	log      *logrus.Logger
+}
+
+func NewEnrichmentHandler(service service.EnrichmentServiceInterface, breakers EnrichmentStatusProvider, log *logrus.Logger) *EnrichmentHandler {
+	if log == nil {
+		log = logrus.New()
+		log.SetFormatter(&logrus.JSONFormatter{})
+		log.SetOutput(os.Stdout)
+		log.SetLevel(logrus.DebugLevel)
+	}
+	return &EnrichmentHandler{
+		service:  service,
+		breakers: breakers,
+		log:      log,
+	}
+}
+
+// GetStatus returns the circuit breaker state of each enrichment provider
+// @Summary Get enrichment provider status
+// @Description Returns the circuit breaker state (closed, open, half-open) of each enrichment provider
+// @Tags enrichment
+// @Produce json
+// @Success 200 {array} domain.EnrichmentProviderStatus
+// @Router /enrichment/status [get]
+func (h *EnrichmentHandler) GetStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, h.breakers.Status())
+}
+
+// EnrichPerson queues a refresh of a person's enriched fields
+// @Summary Re-enrich a person
+// @Description Queues a refresh of age, gender and/or nationality from the providers, overwriting the stored values.
+// @Description Without a body all enrichable fields are refreshed. Fields set manually through PUT /person/{id}
+// @Description are kept unless "force" is true.
+// @Tags enrichment
+// @Accept json
+// @Produce json
+// @Param id path int true "Person ID"
+// @Param request body domain.EnrichRequest false "Fields to refresh"
+// @Success 202 {object} domain.EnrichmentJob
+// @Failure 400 {object} domain.ErrorResponse "Invalid request body or ID"
+// @Failure 404 {object} domain.ErrorResponse "Person not found"
+// @Failure 500 {object} domain.ErrorResponse "Internal server error"
+// @Router /person/{id}/enrich [post]
+func (h *EnrichmentHandler) EnrichPerson(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log.WithError(err).Debug("Invalid ID parameter")
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	request, ok := h.bindEnrichRequest(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.service.EnrichPerson(c.Request.Context(), id, request.Fields, request.Force)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, domain.ErrorResponse{Error: "Person not found"})
+		case errors.Is(err, service.ErrNoProviders):
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: err.Error()})
+		default:
+			h.log.WithError(err).Error("Failed to queue re-enrichment")
+			c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Error: "Failed to queue re-enrichment"})
+		}
+		return
+	}
+
+	h.log.WithFields(logrus.Fields{
+		"id":     id,
+		"job_id": job.ID,
+	}).Info("Re-enrichment queued successfully")
+	c.JSON(http.StatusAccepted, job)
+}
+
+// EnrichPeople queues a refresh for every person matching the filters
+// @Summary Re-enrich matching people
+// @Description Queues a refresh of the given fields for every person matching the same filters as GET /people.
+// @Description Manually set fields are kept unless "force" is true.
+// @Description Progress is reported by GET /enrichment/batches/{id}.
+// @Tags enrichment
+// @Accept json
+// @Produce json
+// @Param name query string false "Filter by name (substring)"
+// @Param surname query string false "Filter by surname (substring)"
+// @Param has_patronymic query bool false "Only people with (true) or without (false) a patronymic"
+// @Param age query int false "Filter by exact age"
+// @Param age_min query int false "Minimum age, inclusive"
+// @Param age_max query int false "Maximum age, inclusive"
+// @Param gender query string false "Comma-separated genders, e.g. male,other"
+// @Param nationality query string false "Comma-separated ISO 3166-1 alpha-2 codes, exact match, e.g. RU,UA"
+// @Param enrichment_status query string false "Filter by enrichment status" Enums(pending, partial, complete, failed)
+// @Param enriched query bool false "Only fully enriched people (true) or the rest (false)"
+// @Param gender_probability_gte query number false "Minimum gender probability (0..1)"
+// @Param nationality_probability_gte query number false "Minimum probability of the stored nationality (0..1)"
+// @Param created_from query string false "Created at or after, RFC 3339 timestamp or date (2006-01-02)"
+// @Param created_to query string false "Created before the timestamp, or on or before the date"
+// @Param request body domain.EnrichRequest false "Fields to refresh"
+// @Success 202 {object} domain.EnrichmentBatch
+// @Failure 400 {object} domain.ErrorResponse "Invalid query parameters or body"
+// @Failure 500 {object} domain.ErrorResponse "Internal server error"
+// @Router /people/enrich [post]
+func (h *EnrichmentHandler) EnrichPeople(c *gin.Context) {
+	filter, ok := parsePersonFilters(c, h.log)
+	if !ok {
+		return
+	}
+	request, ok := h.bindEnrichRequest(c)
+	if !ok {
+		return
+	}
+
+	batch, err := h.service.EnrichPeople(c.Request.Context(), filter, request.Fields, request.Force)
+	if err != nil {
+		if errors.Is(err, service.ErrNoProviders) {
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: err.Error()})
+			return
+		}
+		h.log.WithError(err).Error("Failed to create enrichment batch")
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Error: "Failed to create enrichment batch"})
+		return
+	}
+
+	h.log.WithField("batch_id", batch.ID).Info("Enrichment batch created successfully")
+	c.JSON(http.StatusAccepted, batch)
+}
+
+// GetJob returns an enrichment job
+// @Summary Get an enrichment job
+// @Tags enrichment
+// @Produce json
+// @Param id path int true "Job ID"
+// @Success 200 {object} domain.EnrichmentJob
+// @Failure 400 {object} domain.ErrorResponse "Invalid ID format"
+// @Failure 404 {object} domain.ErrorResponse "Job not found"
+// @Failure 500 {object} domain.ErrorResponse "Internal server error"
+// @Router /enrichment/jobs/{id} [get]
+func (h *EnrichmentHandler) GetJob(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log.WithError(err).Debug("Invalid ID parameter")
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	job, err := h.service.GetJob(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, domain.ErrorResponse{Error: "Job not found"})
+			return
+		}
+		h.log.WithError(err).Error("Failed to get enrichment job")
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Error: "Failed to get enrichment job"})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// GetBatch returns a bulk re-enrichment with its progress
+// @Summary Get a bulk re-enrichment
+// @Description Returns the batch with the number of queued, running, done and failed jobs
+// @Tags enrichment
+// @Produce json
+// @Param id path int true "Batch ID"
+// @Success 200 {object} domain.EnrichmentBatch
+// @Failure 400 {object} domain.ErrorResponse "Invalid ID format"
+// @Failure 404 {object} domain.ErrorResponse "Batch not found"
+// @Failure 500 {object} domain.ErrorResponse "Internal server error"
+// @Router /enrichment/batches/{id} [get]
+func (h *EnrichmentHandler) GetBatch(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log.WithError(err).Debug("Invalid ID parameter")
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	batch, err := h.service.GetBatch(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, domain.ErrorResponse{Error: "Batch not found"})
+			return
+		}
+		h.log.WithError(err).Error("Failed to get enrichment batch")
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Error: "Failed to get enrichment batch"})
+		return
+	}
+	c.JSON(http.StatusOK, batch)
+}
+
+// GetHistory returns the provider calls made for a person
+// @Summary Get the enrichment history of a person
+// @Description Returns the calls made to the enrichment providers for the person, newest first, with status code,
+// @Description latency, error and the raw JSON answer. A batched call lists every name it asked for.
+// @Description Answers served from the cache are not calls and do not show up.
+// @Tags enrichment
+// @Produce json
+// @Param id path int true "Person ID"
+// @Param limit query int false "Maximum number of calls (default 50, max 500)"
+// @Success 200 {array} domain.EnrichmentCall
+// @Failure 400 {object} domain.ErrorResponse "Invalid ID format"
+// @Failure 404 {object} domain.ErrorResponse "Person not found"
+// @Failure 500 {object} domain.ErrorResponse "Internal server error"
+// @Router /person/{id}/enrichment-history [get]
+func (h *EnrichmentHandler) GetHistory(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log.WithError(err).Debug("Invalid ID parameter")
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(historyDefaultLimit)))
+	if err != nil || limit < 1 {
+		limit = historyDefaultLimit
+	}
+	if limit > historyMaxLimit {
+		limit = historyMaxLimit
+	}
+
+	calls, err := h.service.GetHistory(c.Request.Context(), id, limit)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, domain.ErrorResponse{Error: "Person not found"})
+			return
+		}
+		h.log.WithError(err).Error("Failed to get enrichment history")
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Error: "Failed to get enrichment history"})
+		return
+	}
+	c.JSON(http.StatusOK, calls)
+}
+
+// bindEnrichRequest reads the optional body of the re-enrichment endpoints
+func (h *EnrichmentHandler) bindEnrichRequest(c *gin.Context) (domain.EnrichRequest, bool) {
+	var request domain.EnrichRequest
+	if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		h.log.WithError(err).Debug("Failed to bind request")
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: "Invalid request body"})
+		return request, false
+	}
+	return request, true
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"person-service/internal/service"
+	"strings"
+)
+
+// circuitStateValues maps breaker states to the numeric gauge values of the metrics endpoint
+var circuitStateValues = map[string]int{
+	service.CircuitClosed:   0,
+	service.CircuitHalfOpen: 1,
+	service.CircuitOpen:     2,
+}
+
+// Metrics serves enrichment metrics in the Prometheus text exposition format
+func Metrics(breakers EnrichmentStatusProvider, cache service.EnrichmentCacheInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var b strings.Builder
+
+		b.WriteString("# HELP person_service_enrichment_circuit_state Circuit breaker state per provider (0 closed, 1 half-open, 2 open)\n")
+		b.WriteString("# TYPE person_service_enrichment_circuit_state gauge\n")
+		statuses := breakers.Status()
+		for _, status := range statuses {
+			fmt.Fprintf(&b, "person_service_enrichment_circuit_state{provider=%q} %d\n", status.Provider, circuitStateValues[status.State])
+		}
+		b.WriteString("# HELP person_service_enrichment_consecutive_failures Consecutive failed calls per provider\n")
+		b.WriteString("# TYPE person_service_enrichment_consecutive_failures gauge\n")
+		for _, status := range statuses {
+			fmt.Fprintf(&b, "person_service_enrichment_consecutive_failures{provider=%q} %d\n", status.Provider, status.ConsecutiveFailures)
+		}
+
+		stats := cache.Stats()
+		b.WriteString("# HELP person_service_enrichment_cache_hits_total Enrichment cache hits per layer\n")
+		b.WriteString("# TYPE person_service_enrichment_cache_hits_total counter\n")
+		fmt.Fprintf(&b, "person_service_enrichment_cache_hits_total{layer=\"memory\"} %d\n", stats.MemoryHits)
+		fmt.Fprintf(&b, "person_service_enrichment_cache_hits_total{layer=\"store\"} %d\n", stats.StoreHits)
+		b.WriteString("# HELP person_service_enrichment_cache_misses_total Enrichment cache misses\n")
+		b.WriteString("# TYPE person_service_enrichment_cache_misses_total counter\n")
+		fmt.Fprintf(&b, "person_service_enrichment_cache_misses_total %d\n", stats.Misses)
+
+		c.Data(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", []byte(b.String()))
+	}
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"person-service/internal/domain"
+	"time"
+)
+
+const AdminTokenHeader = "X-Admin-Token"
+
+// adminContextKey marks requests authenticated with the admin token
+const adminContextKey = "admin"
+
+// AdminAuth requires the X-Admin-Token header to match token.
+// With an empty token every admin request is rejected.
+func AdminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, domain.ErrorResponse{Error: "Admin API is disabled"})
+			return
+		}
+		provided := c.GetHeader(AdminTokenHeader)
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, domain.ErrorResponse{Error: "Invalid admin token"})
+			return
+		}
+		c.Set(adminContextKey, true)
+		c.Next()
+	}
+}
+
+// IdentifyAdmin marks requests with a valid X-Admin-Token as made by an admin, see isAdmin.
+// Unlike AdminAuth it lets every request through, for endpoints with admin-only options.
+func IdentifyAdmin(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided := c.GetHeader(AdminTokenHeader)
+		if token != "" && subtle.ConstantTimeCompare([]byte(provided), []byte(token)) == 1 {
+			c.Set(adminContextKey, true)
+		}
+		c.Next()
+	}
+}
+
+func isAdmin(c *gin.Context) bool {
+	return c.GetBool(adminContextKey)
+}
+
+// RequestTimeout puts a deadline on the request context, so everything the handler starts with it
+// is cancelled once the deadline passes or the client goes away. A zero timeout only keeps the latter.
+func RequestTimeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
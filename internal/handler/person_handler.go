@@ -2,14 +2,18 @@ package handler
 
 import (
 	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 	"net/http"
+	"net/url"
 	"os"
 	"person-service/internal/domain"
 	"person-service/internal/repository"
 	"person-service/internal/service"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -39,7 +43,11 @@ func NewPersonHandler(service service.PersonServiceInterface, log *logrus.Logger
 
 // CreatePerson creates a new person
 // @Summary Create a new person
-// @Description Creates a person with enriched age, gender, and nationality from external APIs
+// @Description Creates a person and queues enrichment of age, gender, and nationality from external APIs.
+// @Description The person is returned with enrichment_status "pending"; poll GET /person/{id} for the enriched fields.
+// @Description Age and gender are localized to country_id (or the service default), see age_localization and gender_localization.
+// @Description Supplied age, gender and nationality are stored as set manually and not looked up.
+// @Description "enrich" limits the lookup: "all" (default), "none" or a list of fields such as ["age"].
 // @Tags persons
 // @Accept json
 // @Produce json
@@ -56,19 +64,8 @@ func (h *PersonHandler) CreatePerson(c *gin.Context) {
 		return
 	}
 
-	var patronymicPtr *string
-	if request.Patronymic != "" {
-		patronymicPtr = &request.Patronymic
-	}
-
-	person := &domain.Person{
-		Name:       request.Name,
-		Surname:    request.Surname,
-		Patronymic: patronymicPtr,
-		CreatedAt:  time.Now().UTC(),
-	}
-
-	id, err := h.service.Create(c.Request.Context(), person)
+	person := newPerson(request, time.Now().UTC())
+	id, err := h.service.Create(c.Request.Context(), person, request.Enrich)
 	if err != nil {
 		h.log.WithFields(logrus.Fields{
 			"error": err,
@@ -83,14 +80,79 @@ func (h *PersonHandler) CreatePerson(c *gin.Context) {
 	c.JSON(http.StatusCreated, person)
 }
 
+// CreatePeople creates people in bulk
+// @Summary Create people in bulk
+// @Description Creates up to 10000 people in one transaction and queues their enrichment.
+// @Description Workers enrich queued people in groups, sending up to 10 names per provider call.
+// @Description Each person takes the same known values and "enrich" option as POST /person.
+// @Tags persons
+// @Accept json
+// @Produce json
+// @Param people body domain.BulkCreatePeopleRequest true "People to create"
+// @Success 201 {object} domain.BulkCreatePeopleResponse
+// @Failure 400 {object} domain.ErrorResponse "Invalid request body"
+// @Failure 500 {object} domain.ErrorResponse "Internal server error"
+// @Router /people/bulk [post]
+func (h *PersonHandler) CreatePeople(c *gin.Context) {
+	var request domain.BulkCreatePeopleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.log.WithError(err).Debug("Failed to bind request")
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	now := time.Now().UTC()
+	people := make([]*domain.Person, 0, len(request.People))
+	enrich := make([]domain.EnrichSelection, 0, len(request.People))
+	for _, item := range request.People {
+		people = append(people, newPerson(item, now))
+		enrich = append(enrich, item.Enrich)
+	}
+
+	ids, err := h.service.CreateMany(c.Request.Context(), people, enrich)
+	if err != nil {
+		h.log.WithFields(logrus.Fields{
+			"error": err,
+			"count": len(people),
+		}).Error("Failed to create people")
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Error: "Failed to create people"})
+		return
+	}
+
+	h.log.WithField("count", len(ids)).Info("People created successfully")
+	c.JSON(http.StatusCreated, domain.BulkCreatePeopleResponse{IDs: ids, Count: len(ids)})
+}
+
+// newPerson builds the person to store from a create request
+func newPerson(request domain.CreatePersonRequest, createdAt time.Time) *domain.Person {
+	person := &domain.Person{
+		Name:        request.Name,
+		Surname:     request.Surname,
+		Age:         request.Age,
+		Gender:      request.Gender,
+		Nationality: request.Nationality,
+		CreatedAt:   createdAt,
+	}
+	if request.Patronymic != "" {
+		person.Patronymic = &request.Patronymic
+	}
+	if request.CountryID != "" {
+		person.CountryID = &request.CountryID
+	}
+	return person
+}
+
 // GetPerson retrieves a person by ID
 // @Summary Get a person by ID
-// @Description Retrieves a person by their unique ID
+// @Description Retrieves a person by their unique ID. IDs of people merged into another one redirect to the survivor.
 // @Tags persons
 // @Produce json
 // @Param id path int true "Person ID"
+// @Param include_deleted query bool false "Also return a deleted person; requires X-Admin-Token"
 // @Success 200 {object} domain.Person
+// @Success 308 "Person was merged, see Location"
 // @Failure 400 {object} domain.ErrorResponse "Invalid ID format"
+// @Failure 403 {object} domain.ErrorResponse "include_deleted without admin token"
 // @Failure 404 {object} domain.ErrorResponse "Person not found"
 // @Failure 500 {object} domain.ErrorResponse "Internal server error"
 // @Router /person/{id} [get]
@@ -102,8 +164,23 @@ func (h *PersonHandler) GetPerson(c *gin.Context) {
 		return
 	}
 
-	person, err := h.service.GetById(c.Request.Context(), id)
+	includeDeleted, ok := h.includeDeleted(c)
+	if !ok {
+		return
+	}
+
+	person, err := h.service.GetById(c.Request.Context(), id, includeDeleted)
 	if err != nil {
+		var merged *domain.PersonMergedError
+		if errors.As(err, &merged) {
+			location := strings.TrimSuffix(c.Request.URL.Path, c.Param("id")) + strconv.FormatInt(merged.SurvivorID, 10)
+			h.log.WithFields(logrus.Fields{
+				"id":          id,
+				"survivor_id": merged.SurvivorID,
+			}).Debug("Redirecting merged person")
+			c.Redirect(http.StatusPermanentRedirect, location)
+			return
+		}
 		if errors.Is(err, repository.ErrNotFound) {
 			h.log.WithField("id", id).Debug("Person not found")
 			c.JSON(http.StatusNotFound, domain.ErrorResponse{Error: "Person not found"})
@@ -121,17 +198,36 @@ func (h *PersonHandler) GetPerson(c *gin.Context) {
 // GetAll retrieves all persons with pagination and filtering
 // @Summary Get all persons
 // @Description Retrieves a list of persons with optional filters and pagination
+// @Description Pages are selected by page and page_size, or by the cursors returned in meta and in the Link header.
+// @Description A cursor is bound to the sort it was issued for; filters should stay the same while paging.
+// @Description With page and page_size, meta always has page and total_items, unless count=none was requested.
 // @Tags persons
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param page_size query int false "Page size" default(10)
-// @Param name query string false "Filter by name"
-// @Param surname query string false "Filter by surname"
-// @Param age query int false "Filter by age"
-// @Param gender query string false "Filter by gender"
-// @Param nationality query string false "Filter by nationality"
+// @Param cursor query string false "Opaque cursor from meta.next_cursor or meta.prev_cursor; replaces page"
+// @Param limit query int false "Page size for cursor pagination, same as page_size" default(10)
+// @Param count query string false "How meta.total_items is counted: exact, estimated from planner statistics, or not at all (none leaves total_items out)" Enums(exact, estimated, none) default(exact)
+// @Param sort query string false "Comma-separated sort fields (id, name, surname, age, gender, nationality, created_at), prefix - for descending" default(-created_at)
+// @Param name query string false "Filter by name (substring)"
+// @Param surname query string false "Filter by surname (substring)"
+// @Param has_patronymic query bool false "Only people with (true) or without (false) a patronymic"
+// @Param age query int false "Filter by exact age"
+// @Param age_min query int false "Minimum age, inclusive"
+// @Param age_max query int false "Maximum age, inclusive"
+// @Param gender query string false "Comma-separated genders, e.g. male,other"
+// @Param nationality query string false "Comma-separated ISO 3166-1 alpha-2 codes, exact match, e.g. RU,UA"
+// @Param enrichment_status query string false "Filter by enrichment status" Enums(pending, partial, complete, failed)
+// @Param enriched query bool false "Only fully enriched people (true) or the rest (false)"
+// @Param gender_probability_gte query number false "Minimum gender probability (0..1)"
+// @Param nationality_probability_gte query number false "Minimum probability of the stored nationality (0..1)"
+// @Param created_from query string false "Created at or after, RFC 3339 timestamp or date (2006-01-02)"
+// @Param created_to query string false "Created before the timestamp, or on or before the date"
+// @Param include_deleted query bool false "Also list deleted people; requires X-Admin-Token"
 // @Success 200 {object} domain.PersonListResponse
+// @Header 200 {string} Link "RFC 8288 links to the next and prev pages"
 // @Failure 400 {object} domain.ErrorResponse "Invalid query parameters"
+// @Failure 403 {object} domain.ErrorResponse "include_deleted without admin token"
 // @Failure 500 {object} domain.ErrorResponse "Internal server error"
 // @Router /people [get]
 func (h *PersonHandler) GetAll(c *gin.Context) {
@@ -144,57 +240,190 @@ func (h *PersonHandler) GetAll(c *gin.Context) {
 	if err != nil || pageSize < 1 {
 		pageSize = paginationDefaultPageSize
 	}
+	if value := c.Query("limit"); value != "" {
+		if limit, err := strconv.Atoi(value); err == nil && limit > 0 {
+			pageSize = limit
+		}
+	}
 	if pageSize > paginationMaxPageSize {
 		pageSize = paginationMaxPageSize
 	}
 
-	filters := make(map[string]interface{})
-	if name := c.Query("name"); name != "" {
-		filters["name"] = name
+	filter, ok := parsePersonFilters(c, h.log)
+	if !ok {
+		return
 	}
-	if surname := c.Query("surname"); surname != "" {
-		filters["surname"] = surname
+	if filter.IncludeDeleted, ok = h.includeDeleted(c); !ok {
+		return
 	}
-	if ageStr := c.Query("age"); ageStr != "" {
-		if age, err := strconv.Atoi(ageStr); err == nil {
-			filters["age"] = age
-		} else {
-			h.log.WithField("age", ageStr).Debug("Invalid age parameter")
-			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: "Invalid age format"})
-			return
-		}
+	sort, err := domain.ParseSort(c.Query("sort"))
+	if err != nil {
+		h.log.WithError(err).Debug("Invalid sort parameter")
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: "Invalid sort: " + err.Error()})
+		return
 	}
-	if gender := c.Query("gender"); gender != "" {
-		filters["gender"] = gender
+
+	count := c.DefaultQuery("count", domain.CountExact)
+	if !slices.Contains(domain.CountModes, count) {
+		h.log.WithField("count", count).Debug("Invalid count parameter")
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: "Invalid count value"})
+		return
 	}
-	if nationality := c.Query("nationality"); nationality != "" {
-		filters["nationality"] = nationality
+
+	query := domain.PersonQuery{Filter: filter, Sort: sort, Limit: pageSize, Offset: (page - 1) * pageSize, Count: count}
+	if value := c.Query("cursor"); value != "" {
+		cursor, err := domain.DecodeCursor(value)
+		if err != nil {
+			h.log.WithError(err).Debug("Invalid cursor parameter")
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: "Invalid cursor"})
+			return
+		}
+		if c.Query("sort") != "" && domain.FormatSort(sort) != cursor.Sort {
+			h.log.WithField("sort", c.Query("sort")).Debug("Cursor issued for another sort")
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: "Invalid cursor: it was issued for sort " + cursor.Sort})
+			return
+		}
+		query.Sort, _ = domain.ParseSort(cursor.Sort)
+		query.Cursor = cursor
+		query.Offset = 0
+		page = 0
 	}
 
-	persons, total, err := h.service.GetAll(c.Request.Context(), filters, page, pageSize)
+	result, err := h.service.GetAll(c.Request.Context(), query)
 	if err != nil {
+		if errors.Is(err, domain.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: "Invalid cursor"})
+			return
+		}
 		h.log.WithError(err).Error("Failed to get persons")
 		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Error: "Failed to get persons"})
 		return
 	}
 
 	h.log.WithFields(logrus.Fields{
-		"count":     len(persons),
-		"total":     total,
-		"page":      page,
-		"page_size": pageSize,
+		"count":      len(result.People),
+		"total":      result.Total,
+		"page":       page,
+		"page_size":  pageSize,
+		"cursor":     query.Cursor != nil,
+		"count_mode": result.CountMode,
 	}).Info("Persons retrieved successfully")
 
-	response := domain.PersonListResponse{
-		Data: persons,
-		Meta: domain.PaginationMeta{
-			Page:       page,
-			PageSize:   pageSize,
-			TotalItems: total,
-		},
+	meta := paginationMeta(page, pageSize, result)
+	setPaginationLinks(c, pageSize, meta)
+
+	c.JSON(http.StatusOK, domain.PersonListResponse{Data: result.People, Meta: meta})
+}
+
+// paginationMeta describes a page of people. page is 0 for cursor based requests, which get no page number.
+func paginationMeta(page, pageSize int, result *domain.PersonPage) domain.PaginationMeta {
+	meta := domain.PaginationMeta{
+		PageSize:   pageSize,
+		TotalItems: result.Total,
+		CountMode:  result.CountMode,
+	}
+	if page > 0 {
+		meta.Page = &page
+	}
+	if result.Next != nil {
+		meta.NextCursor = result.Next.Encode()
+	}
+	if result.Prev != nil {
+		meta.PrevCursor = result.Prev.Encode()
+	}
+	return meta
+}
+
+// setPaginationLinks sets the RFC 8288 Link header to the next and previous pages of the request's list.
+// The links keep the other query parameters and switch page based requests over to cursors.
+func setPaginationLinks(c *gin.Context, limit int, meta domain.PaginationMeta) {
+	var links []string
+	for _, link := range []struct{ rel, cursor string }{{"next", meta.NextCursor}, {"prev", meta.PrevCursor}} {
+		if link.cursor == "" {
+			continue
+		}
+		query := c.Request.URL.Query()
+		query.Del("page")
+		query.Del("page_size")
+		query.Set("cursor", link.cursor)
+		query.Set("limit", strconv.Itoa(limit))
+		target := url.URL{Path: c.Request.URL.Path, RawQuery: query.Encode()}
+		links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, target.String(), link.rel))
+	}
+	if len(links) > 0 {
+		c.Header("Link", strings.Join(links, ", "))
+	}
+}
+
+// Search finds persons by name
+// @Summary Search persons by name
+// @Description Fuzzy search over name, surname and patronymic, in their original and transliterated forms.
+// @Description Hits are people whose names are similar enough to q (trigram word similarity, tolerates typos)
+// @Description or contain all of its words. Results are ranked by similarity, full word matches first.
+// @Description The list filters of GET /people can narrow the search.
+// @Tags persons
+// @Produce json
+// @Param q query string true "Search query, e.g. Dmitriy Ivanov"
+// @Param limit query int false "Maximum number of results" default(10)
+// @Param min_similarity query number false "Minimum word similarity (0..1) of a hit" default(0.4)
+// @Param gender query string false "Comma-separated genders, e.g. male,other"
+// @Param nationality query string false "Comma-separated ISO 3166-1 alpha-2 codes, exact match, e.g. RU,UA"
+// @Param age_min query int false "Minimum age, inclusive"
+// @Param age_max query int false "Maximum age, inclusive"
+// @Success 200 {object} domain.PersonSearchResponse
+// @Failure 400 {object} domain.ErrorResponse "Invalid query parameters"
+// @Failure 500 {object} domain.ErrorResponse "Internal server error"
+// @Router /people/search [get]
+func (h *PersonHandler) Search(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: "Query parameter q is required"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(paginationDefaultPageSize)))
+	if err != nil || limit < 1 {
+		limit = paginationDefaultPageSize
+	}
+	if limit > paginationMaxPageSize {
+		limit = paginationMaxPageSize
+	}
+
+	minSimilarity := domain.DefaultSearchSimilarity
+	if value := c.Query("min_similarity"); value != "" {
+		minSimilarity, err = strconv.ParseFloat(value, 64)
+		if err != nil || minSimilarity <= 0 || minSimilarity > 1 {
+			h.log.WithField("min_similarity", value).Debug("Invalid min_similarity parameter")
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: "Invalid min_similarity value"})
+			return
+		}
+	}
+
+	filter, ok := parsePersonFilters(c, h.log)
+	if !ok {
+		return
+	}
+
+	results, err := h.service.Search(c.Request.Context(), domain.PersonSearch{
+		Query:         q,
+		MinSimilarity: minSimilarity,
+		Filter:        filter,
+		Limit:         limit,
+	})
+	if err != nil {
+		h.log.WithError(err).Error("Failed to search persons")
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Error: "Failed to search persons"})
+		return
+	}
+	if results == nil {
+		results = []*domain.PersonSearchResult{}
 	}
 
-	c.JSON(http.StatusOK, response)
+	h.log.WithFields(logrus.Fields{
+		"query": q,
+		"count": len(results),
+	}).Info("Persons searched successfully")
+	c.JSON(http.StatusOK, domain.PersonSearchResponse{Data: results, Count: len(results)})
 }
 
 // Update updates a person by ID
@@ -256,7 +485,8 @@ func (h *PersonHandler) Update(c *gin.Context) {
 
 // Delete deletes a person by ID
 // @Summary Delete a person
-// @Description Deletes a person by their ID
+// @Description Deletes a person by their ID. The person can be restored with POST /person/{id}/restore
+// @Description until it is purged after the retention period (PURGE_RETENTION).
 // @Tags persons
 // @Produce json
 // @Param id path int true "Person ID"
@@ -287,3 +517,225 @@ func (h *PersonHandler) Delete(c *gin.Context) {
 	h.log.WithField("id", id).Info("Person deleted successfully")
 	c.Status(http.StatusNoContent)
 }
+
+// Restore undoes the deletion of a person
+// @Summary Restore a deleted person
+// @Description Restores a person deleted with DELETE /person/{id} that has not been purged yet.
+// @Description Restoring a person that is not deleted is a conflict. Restoring a merged duplicate undoes the merge,
+// @Description while the survivor keeps the fields it took over.
+// @Tags persons
+// @Produce json
+// @Param id path int true "Person ID"
+// @Success 200 {object} domain.Person
+// @Failure 400 {object} domain.ErrorResponse "Invalid ID format"
+// @Failure 404 {object} domain.ErrorResponse "Person not found"
+// @Failure 409 {object} domain.ErrorResponse "Person is not deleted"
+// @Failure 500 {object} domain.ErrorResponse "Internal server error"
+// @Router /person/{id}/restore [post]
+func (h *PersonHandler) Restore(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log.WithError(err).Debug("Invalid ID parameter")
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: "Invalid ID format"})
+		return
+	}
+
+	person, err := h.service.Restore(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			h.log.WithField("id", id).Debug("Person not found for restore")
+			c.JSON(http.StatusNotFound, domain.ErrorResponse{Error: "Person not found"})
+			return
+		}
+		if errors.Is(err, repository.ErrNotDeleted) {
+			c.JSON(http.StatusConflict, domain.ErrorResponse{Error: "Person is not deleted"})
+			return
+		}
+		h.log.WithError(err).Error("Failed to restore person")
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Error: "Failed to restore person"})
+		return
+	}
+
+	h.log.WithField("id", id).Info("Person restored successfully")
+	c.JSON(http.StatusOK, person)
+}
+
+// includeDeleted reads the admin-only include_deleted parameter.
+// On invalid or unauthorized use it writes an error response and returns false.
+func (h *PersonHandler) includeDeleted(c *gin.Context) (bool, bool) {
+	value := c.Query("include_deleted")
+	if value == "" {
+		return false, true
+	}
+	include, err := strconv.ParseBool(value)
+	if err != nil {
+		h.log.WithField("include_deleted", value).Debug("Invalid include_deleted parameter")
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: "Invalid include_deleted value"})
+		return false, false
+	}
+	if include && !isAdmin(c) {
+		c.JSON(http.StatusForbidden, domain.ErrorResponse{Error: "include_deleted requires an admin token"})
+		return false, false
+	}
+	return include, true
+}
+
+// parsePersonFilters reads the list filters shared by GET /people and POST /people/enrich.
+// On invalid input it writes a 400 response and returns false.
+func parsePersonFilters(c *gin.Context, log *logrus.Logger) (domain.PersonFilter, bool) {
+	var filter domain.PersonFilter
+	invalid := func(key, value string) (domain.PersonFilter, bool) {
+		log.WithField(key, value).Debugf("Invalid %s parameter", key)
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Error: "Invalid " + key + " value"})
+		return filter, false
+	}
+
+	if name := c.Query("name"); name != "" {
+		filter.Name = domain.StringContains(name)
+	}
+	if surname := c.Query("surname"); surname != "" {
+		filter.Surname = domain.StringContains(surname)
+	}
+	if value := c.Query("has_patronymic"); value != "" {
+		has, err := strconv.ParseBool(value)
+		if err != nil {
+			return invalid("has_patronymic", value)
+		}
+		missing := !has
+		filter.Patronymic = &domain.StringFilter{Null: &missing}
+	}
+
+	ages := []struct {
+		key   string
+		bound func(f *domain.IntFilter) **int
+	}{
+		{"age", func(f *domain.IntFilter) **int { return &f.Eq }},
+		{"age_min", func(f *domain.IntFilter) **int { return &f.Min }},
+		{"age_max", func(f *domain.IntFilter) **int { return &f.Max }},
+	}
+	for _, a := range ages {
+		value := c.Query(a.key)
+		if value == "" {
+			continue
+		}
+		age, err := strconv.Atoi(value)
+		if err != nil || age < 0 {
+			return invalid(a.key, value)
+		}
+		if filter.Age == nil {
+			filter.Age = &domain.IntFilter{}
+		}
+		*a.bound(filter.Age) = &age
+	}
+
+	if value := c.Query("gender"); value != "" {
+		genders := splitList(value)
+		for _, gender := range genders {
+			if gender != "male" && gender != "female" && gender != "other" {
+				return invalid("gender", value)
+			}
+		}
+		filter.Gender = &domain.StringFilter{In: genders}
+	}
+	if value := c.Query("nationality"); value != "" {
+		countries := splitList(strings.ToUpper(value))
+		for _, country := range countries {
+			if !isCountryCode(country) {
+				return invalid("nationality", value)
+			}
+		}
+		filter.Nationality = &domain.StringFilter{In: countries}
+	}
+
+	if status := c.Query("enrichment_status"); status != "" {
+		switch status {
+		case domain.EnrichmentPending, domain.EnrichmentPartial, domain.EnrichmentComplete, domain.EnrichmentFailed:
+			filter.EnrichmentStatus = domain.StringEq(status)
+		default:
+			return invalid("enrichment_status", status)
+		}
+	}
+	if value := c.Query("enriched"); value != "" {
+		enriched, err := strconv.ParseBool(value)
+		if err != nil {
+			return invalid("enriched", value)
+		}
+		if filter.EnrichmentStatus == nil {
+			filter.EnrichmentStatus = &domain.StringFilter{}
+		}
+		if enriched {
+			filter.EnrichmentStatus.In = []string{domain.EnrichmentComplete}
+		} else {
+			filter.EnrichmentStatus.In = []string{domain.EnrichmentPending, domain.EnrichmentPartial, domain.EnrichmentFailed}
+		}
+	}
+
+	probabilities := []struct {
+		key    string
+		filter **domain.FloatFilter
+	}{
+		{"gender_probability_gte", &filter.GenderProbability},
+		{"nationality_probability_gte", &filter.NationalityProbability},
+	}
+	for _, p := range probabilities {
+		value := c.Query(p.key)
+		if value == "" {
+			continue
+		}
+		probability, err := strconv.ParseFloat(value, 64)
+		if err != nil || probability < 0 || probability > 1 {
+			return invalid(p.key, value)
+		}
+		*p.filter = &domain.FloatFilter{Min: &probability}
+	}
+
+	if value := c.Query("created_from"); value != "" {
+		from, _, err := parseTimeParam(value)
+		if err != nil {
+			return invalid("created_from", value)
+		}
+		filter.CreatedAt = &domain.TimeFilter{From: &from}
+	}
+	if value := c.Query("created_to"); value != "" {
+		to, dateOnly, err := parseTimeParam(value)
+		if err != nil {
+			return invalid("created_to", value)
+		}
+		// A bare date includes the whole day
+		if dateOnly {
+			to = to.AddDate(0, 0, 1)
+		}
+		if filter.CreatedAt == nil {
+			filter.CreatedAt = &domain.TimeFilter{}
+		}
+		filter.CreatedAt.To = &to
+	}
+	return filter, true
+}
+
+// splitList splits a comma-separated query value, dropping blanks and duplicates
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" && !slices.Contains(items, item) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func isCountryCode(value string) bool {
+	return len(value) == 2 && value[0] >= 'A' && value[0] <= 'Z' && value[1] >= 'A' && value[1] <= 'Z'
+}
+
+// parseTimeParam accepts an RFC 3339 timestamp or a date (2006-01-02, UTC) and reports which one it got.
+// The time is returned in UTC, since created_at is a timestamp without time zone holding UTC
+// and pgx drops the offset of a time bound to it.
+func parseTimeParam(value string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	return t.UTC(), false, err
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"person-service/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func TestParseTimeParam(t *testing.T) {
+	tests := []struct {
+		value    string
+		want     time.Time
+		dateOnly bool
+		wantErr  bool
+	}{
+		{value: "2024-01-01", want: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), dateOnly: true},
+		{value: "2024-01-01T00:00:00Z", want: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{value: "2024-01-01T00:00:00+03:00", want: time.Date(2023, 12, 31, 21, 0, 0, 0, time.UTC)},
+		{value: "2024-01-01T10:30:00-05:00", want: time.Date(2024, 1, 1, 15, 30, 0, 0, time.UTC)},
+		{value: "01.01.2024", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, dateOnly, err := parseTimeParam(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			// Equal compares instants; the location must be UTC as well, since the offset is lost in the database
+			if !got.Equal(tt.want) || got.Location() != time.UTC {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if dateOnly != tt.dateOnly {
+				t.Errorf("dateOnly = %v, want %v", dateOnly, tt.dateOnly)
+			}
+		})
+	}
+}
+
+func TestParsePersonFiltersCreatedAt(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/api/people?created_from=2024-01-01T00:00:00%2B03:00&created_to=2024-01-31", nil)
+
+	filter, ok := parsePersonFilters(c, log)
+	if !ok {
+		t.Fatal("filters rejected")
+	}
+	if filter.CreatedAt == nil || filter.CreatedAt.From == nil || filter.CreatedAt.To == nil {
+		t.Fatalf("created_at filter incomplete: %+v", filter.CreatedAt)
+	}
+	if want := time.Date(2023, 12, 31, 21, 0, 0, 0, time.UTC); !filter.CreatedAt.From.Equal(want) || filter.CreatedAt.From.Location() != time.UTC {
+		t.Errorf("from = %v, want %v", *filter.CreatedAt.From, want)
+	}
+	if want := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC); !filter.CreatedAt.To.Equal(want) {
+		t.Errorf("to = %v, want %v", *filter.CreatedAt.To, want)
+	}
+}
+
+func TestPaginationMeta(t *testing.T) {
+	total := 0
+	tests := []struct {
+		name   string
+		page   int
+		result *domain.PersonPage
+		want   string
+	}{
+		{
+			name:   "page with an empty count",
+			page:   3,
+			result: &domain.PersonPage{Total: &total, CountMode: domain.CountExact},
+			want:   `{"page":3,"page_size":20,"total_items":0,"count_mode":"exact"}`,
+		},
+		{
+			name:   "page without count",
+			page:   1,
+			result: &domain.PersonPage{CountMode: domain.CountNone},
+			want:   `{"page":1,"page_size":20,"count_mode":"none"}`,
+		},
+		{
+			name:   "cursor",
+			result: &domain.PersonPage{Total: &total, CountMode: domain.CountEstimated},
+			want:   `{"page_size":20,"total_items":0,"count_mode":"estimated"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(paginationMeta(tt.page, 20, tt.result))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("got %s, want %s", data, tt.want)
+			}
+		})
+	}
+}
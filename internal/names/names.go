@@ -0,0 +1,76 @@
+// Package names cleans up person names before they are stored and derives the Latin keys
+// used to query the enrichment providers and to search regardless of script.
+package names
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalizer applies the configured transliteration scheme. The zero value does not transliterate.
+type Normalizer struct {
+	scheme Scheme
+}
+
+func NewNormalizer(scheme Scheme) (*Normalizer, error) {
+	switch scheme {
+	case SchemeNone, SchemeICAO, SchemeGOST:
+		return &Normalizer{scheme: scheme}, nil
+	default:
+		return nil, fmt.Errorf("unknown transliteration scheme: %s", scheme)
+	}
+}
+
+func (n *Normalizer) Scheme() Scheme {
+	return n.scheme
+}
+
+// Clean trims name, collapses inner whitespace and converts it to Unicode NFC
+func Clean(name string) string {
+	return strings.Join(strings.Fields(norm.NFC.String(name)), " ")
+}
+
+// Display returns the form of name that is stored and shown: cleaned and, for words typed
+// entirely in one case, capitalized. Mixed-case words such as "McDonald" are kept as typed.
+// Words are split on spaces, hyphens and apostrophes, so "anna-maria" becomes "Anna-Maria".
+func Display(name string) string {
+	name = Clean(name)
+	var b strings.Builder
+	b.Grow(len(name))
+	start := 0
+	for i, r := range name {
+		if isWordSeparator(r) {
+			b.WriteString(capitalize(name[start:i]))
+			b.WriteRune(r)
+			start = i + utf8.RuneLen(r)
+		}
+	}
+	b.WriteString(capitalize(name[start:]))
+	return b.String()
+}
+
+// Key returns the lower-case Latin form of name used as enrichment key and for search.
+// Cyrillic is transliterated with the configured scheme; other scripts are only lower-cased.
+func (n *Normalizer) Key(name string) string {
+	name = strings.ToLower(Clean(name))
+	if n == nil || (n.scheme != SchemeICAO && n.scheme != SchemeGOST) {
+		return name
+	}
+	return transliterate(n.scheme, name)
+}
+
+func isWordSeparator(r rune) bool {
+	return r == ' ' || r == '-' || r == '\'' || r == '’'
+}
+
+func capitalize(word string) string {
+	if word == "" || (word != strings.ToLower(word) && word != strings.ToUpper(word)) {
+		return word
+	}
+	first, size := utf8.DecodeRuneInString(word)
+	return string(unicode.ToTitle(first)) + strings.ToLower(word[size:])
+}
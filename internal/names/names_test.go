@@ -0,0 +1,137 @@
+package names
+
+import "testing"
+
+func TestClean(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "trimmed", in: "  Anna ", want: "Anna"},
+		{name: "inner whitespace collapsed", in: "Anna \t Maria\n", want: "Anna Maria"},
+		{name: "decomposed Latin composed", in: "Jose\u0301", want: "Jos\u00e9"},
+		{name: "decomposed Cyrillic composed", in: "Андреи\u0306", want: "Андре\u0439"},
+		{name: "empty", in: "   ", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Clean(tt.in); got != tt.want {
+				t.Errorf("Clean(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDisplay(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "ivan", want: "Ivan"},
+		{in: "IVAN", want: "Ivan"},
+		{in: "иван", want: "Иван"},
+		{in: "  мария   ивановна ", want: "Мария Ивановна"},
+		{in: "McDonald", want: "McDonald"},
+		{in: "MacLEAN", want: "MacLEAN"},
+		{in: "anna-maria", want: "Anna-Maria"},
+		{in: "ANNA-maria", want: "Anna-Maria"},
+		{in: "o'brien", want: "O'Brien"},
+		{in: "d’artagnan", want: "D’Artagnan"},
+		{in: "jean--luc", want: "Jean--Luc"},
+		{in: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := Display(tt.in); got != tt.want {
+				t.Errorf("Display(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewNormalizer(t *testing.T) {
+	for _, scheme := range []Scheme{SchemeNone, SchemeICAO, SchemeGOST} {
+		n, err := NewNormalizer(scheme)
+		if err != nil || n.Scheme() != scheme {
+			t.Errorf("NewNormalizer(%q) = %v, %v", scheme, n, err)
+		}
+	}
+	if _, err := NewNormalizer("bgn"); err == nil {
+		t.Error("expected an error for an unknown scheme")
+	}
+}
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		scheme Scheme
+		in     string
+		want   string
+	}{
+		{scheme: SchemeNone, in: " Дмитрий ", want: "дмитрий"},
+		{scheme: SchemeNone, in: "IVAN", want: "ivan"},
+
+		{scheme: SchemeICAO, in: "Дмитрий", want: "dmitrii"},
+		{scheme: SchemeICAO, in: "Юлия", want: "iuliia"},
+		{scheme: SchemeICAO, in: "Хрущёв", want: "khrushchev"},
+		{scheme: SchemeICAO, in: "Цветков", want: "tsvetkov"},
+		{scheme: SchemeICAO, in: "Лицей", want: "litsei"},
+		{scheme: SchemeICAO, in: "Объедков", want: "obieedkov"},
+		{scheme: SchemeICAO, in: "Игорь", want: "igor"},
+		{scheme: SchemeICAO, in: "Жанна", want: "zhanna"},
+		{scheme: SchemeICAO, in: "Їжак", want: "izhak"},
+		{scheme: SchemeICAO, in: "Євген", want: "ievgen"},
+		{scheme: SchemeICAO, in: "Анна-Мария", want: "anna-mariia"},
+		{scheme: SchemeICAO, in: "Андреи\u0306", want: "andrei"},
+		{scheme: SchemeICAO, in: "  José   GARCÍA ", want: "josé garcía"},
+
+		{scheme: SchemeGOST, in: "Дмитрий", want: "dmitrij"},
+		{scheme: SchemeGOST, in: "Юлия", want: "yuliya"},
+		{scheme: SchemeGOST, in: "Хрущёв", want: "xrushhyov"},
+		{scheme: SchemeGOST, in: "Объедков", want: "obedkov"},
+		{scheme: SchemeGOST, in: "Їжак", want: "yizhak"},
+		{scheme: SchemeGOST, in: "Євген", want: "yevgen"},
+		// ц is c before i, e, y and j, and cz elsewhere
+		{scheme: SchemeGOST, in: "Цветков", want: "czvetkov"},
+		{scheme: SchemeGOST, in: "Царёв", want: "czaryov"},
+		{scheme: SchemeGOST, in: "Кузнец", want: "kuznecz"},
+		{scheme: SchemeGOST, in: "Лицей", want: "licej"},
+		{scheme: SchemeGOST, in: "Ципко", want: "cipko"},
+		{scheme: SchemeGOST, in: "Цыганов", want: "cyganov"},
+		{scheme: SchemeGOST, in: "Кацюба", want: "kacyuba"},
+		{scheme: SchemeGOST, in: "Яцйо", want: "yacjo"},
+		{scheme: SchemeGOST, in: "Цьолковский", want: "czolkovskij"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.scheme)+"/"+tt.in, func(t *testing.T) {
+			n, err := NewNormalizer(tt.scheme)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := n.Key(tt.in); got != tt.want {
+				t.Errorf("Key(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyWithoutNormalizer(t *testing.T) {
+	var n *Normalizer
+	if got := n.Key(" Дмитрий "); got != "дмитрий" {
+		t.Errorf("Key = %q, want %q", got, "дмитрий")
+	}
+}
+
+func TestTransliterationTablesCoverAlphabet(t *testing.T) {
+	for _, r := range "абвгдеёжзийклмнопрстуфхцчшщъыьэюяіїєґў" {
+		if _, ok := icao[r]; !ok {
+			t.Errorf("ICAO has no transliteration of %c", r)
+		}
+		if _, ok := gost[r]; !ok {
+			t.Errorf("GOST has no transliteration of %c", r)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package names
+
+import "strings"
+
+// Scheme selects how Cyrillic names are transliterated to Latin
+type Scheme string
+
+const (
+	SchemeNone Scheme = "none"
+	// SchemeICAO follows ICAO Doc 9303, as used in Russian and Ukrainian passports: Дмитрий → dmitrii
+	SchemeICAO Scheme = "icao"
+	// SchemeGOST follows GOST 7.79-2000 system B without its diacritic marks: Дмитрий → dmitrij
+	SchemeGOST Scheme = "gost"
+)
+
+var icao = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "i", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "ie", 'ы': "y", 'ь': "", 'э': "e", 'ю': "iu",
+	'я': "ia", 'і': "i", 'ї': "i", 'є': "ie", 'ґ': "g", 'ў': "u",
+}
+
+var gost = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "j", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "x", 'ц': "cz",
+	'ч': "ch", 'ш': "sh", 'щ': "shh", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya", 'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g", 'ў': "u",
+}
+
+// transliterate converts the lower-case Cyrillic letters of name, leaving everything else as is
+func transliterate(scheme Scheme, name string) string {
+	table := icao
+	if scheme == SchemeGOST {
+		table = gost
+	}
+
+	runes := []rune(name)
+	var b strings.Builder
+	b.Grow(len(name))
+	for i, r := range runes {
+		latin, ok := table[r]
+		if !ok {
+			b.WriteRune(r)
+			continue
+		}
+		// GOST writes ц as c before i, e, y and j
+		if scheme == SchemeGOST && r == 'ц' && i+1 < len(runes) {
+			if next := table[runes[i+1]]; next != "" && strings.ContainsRune("ieyj", rune(next[0])) {
+				latin = "c"
+			}
+		}
+		b.WriteString(latin)
+	}
+	return b.String()
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+	"person-service/internal/domain"
+	"strings"
+	"time"
+)
+
+type EnrichmentCacheRepositoryInterface interface {
+	// Get returns ErrNotFound for missing and expired entries
+	Get(ctx context.Context, provider, name string) (*domain.EnrichmentCacheEntry, error)
+	// Set stores entry, whose FetchedAt is expected in UTC
+	Set(ctx context.Context, entry *domain.EnrichmentCacheEntry) error
+	// Purge deletes entries matching provider and name; empty values match everything.
+	// A name also matches its localized entries, stored as "name|country_id".
+	Purge(ctx context.Context, provider, name string) (int64, error)
+}
+
+type EnrichmentCacheRepository struct {
+	db  *pgxpool.Pool
+	log *logrus.Logger
+}
+
+func NewEnrichmentCacheRepository(db *pgxpool.Pool, log *logrus.Logger) EnrichmentCacheRepositoryInterface {
+	return &EnrichmentCacheRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+func (r *EnrichmentCacheRepository) Get(ctx context.Context, provider, name string) (*domain.EnrichmentCacheEntry, error) {
+	query := `
+		SELECT name, provider, payload, fetched_at
+		FROM enrichment_cache
+		WHERE provider = $1 AND name = $2 AND fetched_at + ttl > $3
+	`
+	// fetched_at holds UTC without a zone, so it is compared with the UTC time rather than the session's clock
+	entry := &domain.EnrichmentCacheEntry{}
+	err := r.db.QueryRow(ctx, query, provider, name, time.Now().UTC()).Scan(
+		&entry.Name, &entry.Provider, &entry.Payload, &entry.FetchedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		r.log.WithError(err).Errorf("Failed to get %s cache entry for %s", provider, name)
+		return nil, fmt.Errorf("failed to get cache entry: %w", err)
+	}
+	return entry, nil
+}
+
+func (r *EnrichmentCacheRepository) Set(ctx context.Context, entry *domain.EnrichmentCacheEntry) error {
+	query := `
+		INSERT INTO enrichment_cache (name, provider, payload, fetched_at, ttl)
+		VALUES ($1, $2, $3, $4, make_interval(secs => $5))
+		ON CONFLICT (provider, name) DO UPDATE
+		SET payload = EXCLUDED.payload, fetched_at = EXCLUDED.fetched_at, ttl = EXCLUDED.ttl
+	`
+	_, err := r.db.Exec(ctx, query,
+		entry.Name,
+		entry.Provider,
+		entry.Payload,
+		entry.FetchedAt,
+		entry.TTL.Seconds(),
+	)
+	if err != nil {
+		r.log.WithError(err).Errorf("Failed to store %s cache entry for %s", entry.Provider, entry.Name)
+		return fmt.Errorf("failed to store cache entry: %w", err)
+	}
+	return nil
+}
+
+func (r *EnrichmentCacheRepository) Purge(ctx context.Context, provider, name string) (int64, error) {
+	query := "DELETE FROM enrichment_cache"
+	var args []interface{}
+	var conditions []string
+	if provider != "" {
+		args = append(args, provider)
+		conditions = append(conditions, fmt.Sprintf("provider = $%d", len(args)))
+	}
+	if name != "" {
+		args = append(args, name)
+		conditions = append(conditions, fmt.Sprintf("(name = $%[1]d OR starts_with(name, $%[1]d || '|'))", len(args)))
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		r.log.WithError(err).Error("Failed to purge enrichment cache")
+		return 0, fmt.Errorf("failed to purge enrichment cache: %w", err)
+	}
+	r.log.WithFields(logrus.Fields{
+		"provider": provider,
+		"name":     name,
+		"purged":   result.RowsAffected(),
+	}).Info("Purged enrichment cache")
+	return result.RowsAffected(), nil
+}
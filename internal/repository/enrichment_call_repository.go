@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+	"person-service/internal/domain"
+)
+
+type EnrichmentCallRepositoryInterface interface {
+	// Record stores call and fills in its ID and creation time
+	Record(ctx context.Context, call *domain.EnrichmentCall) error
+	// ListByPerson returns up to limit calls made for the person, newest first
+	ListByPerson(ctx context.Context, personID int64, limit int) ([]*domain.EnrichmentCall, error)
+}
+
+type EnrichmentCallRepository struct {
+	db  *pgxpool.Pool
+	log *logrus.Logger
+}
+
+func NewEnrichmentCallRepository(db *pgxpool.Pool, log *logrus.Logger) EnrichmentCallRepositoryInterface {
+	return &EnrichmentCallRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+func (r *EnrichmentCallRepository) Record(ctx context.Context, call *domain.EnrichmentCall) error {
+	query := `
+		INSERT INTO enrichment_calls (provider, names, country_id, person_ids, status_code, latency_ms, response, error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+	personIDs := call.PersonIDs
+	if personIDs == nil {
+		personIDs = []int64{}
+	}
+	var response interface{}
+	if len(call.Response) > 0 {
+		response = call.Response
+	}
+	err := r.db.QueryRow(ctx, query,
+		call.Provider,
+		call.Names,
+		call.CountryID,
+		personIDs,
+		call.StatusCode,
+		call.LatencyMs,
+		response,
+		call.Error,
+	).Scan(&call.ID, &call.CreatedAt)
+	if err != nil {
+		r.log.WithError(err).Errorf("Failed to record %s call", call.Provider)
+		return fmt.Errorf("failed to record enrichment call: %w", err)
+	}
+	return nil
+}
+
+func (r *EnrichmentCallRepository) ListByPerson(ctx context.Context, personID int64, limit int) ([]*domain.EnrichmentCall, error) {
+	query := `
+		SELECT id, provider, names, country_id, person_ids, status_code, latency_ms, response, error, created_at
+		FROM enrichment_calls
+		WHERE person_ids @> ARRAY[$1::integer]
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2
+	`
+	rows, err := r.db.Query(ctx, query, personID, limit)
+	if err != nil {
+		r.log.WithError(err).Errorf("Failed to list enrichment calls of person %d", personID)
+		return nil, fmt.Errorf("failed to list enrichment calls: %w", err)
+	}
+	defer rows.Close()
+
+	calls := []*domain.EnrichmentCall{}
+	for rows.Next() {
+		call := &domain.EnrichmentCall{}
+		if err := rows.Scan(
+			&call.ID, &call.Provider, &call.Names, &call.CountryID, &call.PersonIDs, &call.StatusCode,
+			&call.LatencyMs, &call.Response, &call.Error, &call.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan enrichment call: %w", err)
+		}
+		calls = append(calls, call)
+	}
+	return calls, rows.Err()
+}
@@ -0,0 +1,291 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+	"person-service/internal/domain"
+	"strings"
+	"time"
+)
+
+type EnrichmentJobRepositoryInterface interface {
+	// Enqueue stores job and fills in its ID, status and timestamps
+	Enqueue(ctx context.Context, job *domain.EnrichmentJob) error
+	// EnqueueMany queues one job with the same providers and fields for each of personIDs
+	EnqueueMany(ctx context.Context, personIDs []int64, providers, fields []string) error
+	GetById(ctx context.Context, id int64) (*domain.EnrichmentJob, error)
+	// Claim locks up to limit due jobs for the caller and marks them running.
+	// Running jobs not finished within lockTimeout are claimed again. Returns ErrNotFound when nothing is due.
+	Claim(ctx context.Context, lockTimeout time.Duration, limit int) ([]*domain.EnrichmentJob, error)
+	Complete(ctx context.Context, id int64) error
+	// Retry puts the job back in the queue for the remaining providers
+	Retry(ctx context.Context, id int64, providers []string, runAt time.Time, lastError string) error
+	Fail(ctx context.Context, id int64, lastError string) error
+
+	CreateBatch(ctx context.Context, batch *domain.EnrichmentBatch) error
+	// EnqueueBatch queues a refresh job for every person matching the batch filters and marks the batch running
+	EnqueueBatch(ctx context.Context, batch *domain.EnrichmentBatch, providers []string) error
+	FailBatch(ctx context.Context, id int64, reason string) error
+	// GetBatch returns the batch with the progress counters of its jobs
+	GetBatch(ctx context.Context, id int64) (*domain.EnrichmentBatch, error)
+}
+
+const enrichmentJobColumns = "id, person_id, providers, fields, refresh, force, batch_id, status, attempts, last_error, run_at, created_at"
+
+func scanEnrichmentJob(row pgx.Row) (*domain.EnrichmentJob, error) {
+	job := &domain.EnrichmentJob{}
+	err := row.Scan(
+		&job.ID, &job.PersonID, &job.Providers, &job.Fields, &job.Refresh, &job.Force, &job.BatchID,
+		&job.Status, &job.Attempts, &job.LastError, &job.RunAt, &job.CreatedAt,
+	)
+	return job, err
+}
+
+type EnrichmentJobRepository struct {
+	db  *pgxpool.Pool
+	log *logrus.Logger
+}
+
+func NewEnrichmentJobRepository(db *pgxpool.Pool, log *logrus.Logger) EnrichmentJobRepositoryInterface {
+	return &EnrichmentJobRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+func (r *EnrichmentJobRepository) Enqueue(ctx context.Context, job *domain.EnrichmentJob) error {
+	query := `
+		INSERT INTO enrichment_jobs (person_id, providers, fields, refresh, force, batch_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, status, run_at, created_at
+	`
+	err := r.db.QueryRow(ctx, query, job.PersonID, job.Providers, job.Fields, job.Refresh, job.Force, job.BatchID).Scan(
+		&job.ID, &job.Status, &job.RunAt, &job.CreatedAt,
+	)
+	if err != nil {
+		r.log.WithError(err).Errorf("Failed to enqueue enrichment of person %d", job.PersonID)
+		return fmt.Errorf("failed to enqueue enrichment job: %w", err)
+	}
+	r.log.Debugf("Enqueued enrichment job %d for person %d", job.ID, job.PersonID)
+	return nil
+}
+
+func (r *EnrichmentJobRepository) EnqueueMany(ctx context.Context, personIDs []int64, providers, fields []string) error {
+	query := `
+		INSERT INTO enrichment_jobs (person_id, providers, fields)
+		SELECT person_id, $2, $3 FROM unnest($1::bigint[]) AS person_id
+	`
+	if _, err := r.db.Exec(ctx, query, personIDs, providers, fields); err != nil {
+		r.log.WithError(err).Errorf("Failed to enqueue enrichment of %d people", len(personIDs))
+		return fmt.Errorf("failed to enqueue enrichment jobs: %w", err)
+	}
+	r.log.Debugf("Enqueued enrichment jobs for %d people", len(personIDs))
+	return nil
+}
+
+func (r *EnrichmentJobRepository) GetById(ctx context.Context, id int64) (*domain.EnrichmentJob, error) {
+	query := "SELECT " + enrichmentJobColumns + " FROM enrichment_jobs WHERE id = $1"
+	job, err := scanEnrichmentJob(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		r.log.WithError(err).Errorf("Failed to get enrichment job %d", id)
+		return nil, fmt.Errorf("failed to get enrichment job: %w", err)
+	}
+	return job, nil
+}
+
+func (r *EnrichmentJobRepository) Claim(ctx context.Context, lockTimeout time.Duration, limit int) ([]*domain.EnrichmentJob, error) {
+	query := `
+		UPDATE enrichment_jobs
+		SET status = 'running', attempts = attempts + 1, locked_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id IN (
+			SELECT id FROM enrichment_jobs
+			WHERE (status = 'queued' AND run_at <= CURRENT_TIMESTAMP)
+			   OR (status = 'running' AND locked_at < CURRENT_TIMESTAMP - make_interval(secs => $1))
+			ORDER BY run_at, id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + enrichmentJobColumns
+	rows, err := r.db.Query(ctx, query, lockTimeout.Seconds(), limit)
+	if err != nil {
+		r.log.WithError(err).Error("Failed to claim enrichment jobs")
+		return nil, fmt.Errorf("failed to claim enrichment jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*domain.EnrichmentJob
+	for rows.Next() {
+		job, err := scanEnrichmentJob(rows)
+		if err != nil {
+			r.log.WithError(err).Error("Failed to scan enrichment job")
+			return nil, fmt.Errorf("failed to scan enrichment job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		r.log.WithError(err).Error("Failed to claim enrichment jobs")
+		return nil, fmt.Errorf("failed to claim enrichment jobs: %w", err)
+	}
+	if len(jobs) == 0 {
+		return nil, ErrNotFound
+	}
+	return jobs, nil
+}
+
+func (r *EnrichmentJobRepository) Complete(ctx context.Context, id int64) error {
+	query := `
+		UPDATE enrichment_jobs
+		SET status = 'done', locked_at = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+	if _, err := r.db.Exec(ctx, query, id); err != nil {
+		r.log.WithError(err).Errorf("Failed to complete enrichment job %d", id)
+		return fmt.Errorf("failed to complete enrichment job: %w", err)
+	}
+	return nil
+}
+
+func (r *EnrichmentJobRepository) Retry(ctx context.Context, id int64, providers []string, runAt time.Time, lastError string) error {
+	query := `
+		UPDATE enrichment_jobs
+		SET status = 'queued', providers = $2, run_at = $3, last_error = $4, locked_at = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+	if _, err := r.db.Exec(ctx, query, id, providers, runAt, lastError); err != nil {
+		r.log.WithError(err).Errorf("Failed to requeue enrichment job %d", id)
+		return fmt.Errorf("failed to requeue enrichment job: %w", err)
+	}
+	return nil
+}
+
+func (r *EnrichmentJobRepository) Fail(ctx context.Context, id int64, lastError string) error {
+	query := `
+		UPDATE enrichment_jobs
+		SET status = 'failed', last_error = $2, locked_at = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+	if _, err := r.db.Exec(ctx, query, id, lastError); err != nil {
+		r.log.WithError(err).Errorf("Failed to mark enrichment job %d as failed", id)
+		return fmt.Errorf("failed to mark enrichment job as failed: %w", err)
+	}
+	return nil
+}
+
+func (r *EnrichmentJobRepository) CreateBatch(ctx context.Context, batch *domain.EnrichmentBatch) error {
+	filters, err := json.Marshal(batch.Filters)
+	if err != nil {
+		return fmt.Errorf("failed to encode batch filters: %w", err)
+	}
+	query := `
+		INSERT INTO enrichment_batches (filters, fields, force)
+		VALUES ($1, $2, $3)
+		RETURNING id, status, created_at
+	`
+	if err := r.db.QueryRow(ctx, query, filters, batch.Fields, batch.Force).Scan(&batch.ID, &batch.Status, &batch.CreatedAt); err != nil {
+		r.log.WithError(err).Error("Failed to create enrichment batch")
+		return fmt.Errorf("failed to create enrichment batch: %w", err)
+	}
+	return nil
+}
+
+func (r *EnrichmentJobRepository) EnqueueBatch(ctx context.Context, batch *domain.EnrichmentBatch, providers []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		r.log.WithError(err).Error("Failed to begin transaction")
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	conditions, args := personConditions(batch.Filters, 5)
+	query := `
+		INSERT INTO enrichment_jobs (person_id, providers, fields, refresh, force, batch_id)
+		SELECT id, $1, $2, true, $3, $4 FROM people`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append([]interface{}{providers, batch.Fields, batch.Force, batch.ID}, args...)
+
+	result, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		r.log.WithError(err).Errorf("Failed to enqueue enrichment batch %d", batch.ID)
+		return fmt.Errorf("failed to enqueue enrichment batch: %w", err)
+	}
+	batch.Total = int(result.RowsAffected())
+	batch.Status = domain.BatchRunning
+
+	_, err = tx.Exec(ctx,
+		"UPDATE people SET enrichment_status = 'pending' WHERE id IN (SELECT person_id FROM enrichment_jobs WHERE batch_id = $1)",
+		batch.ID,
+	)
+	if err != nil {
+		r.log.WithError(err).Errorf("Failed to reset enrichment status for batch %d", batch.ID)
+		return fmt.Errorf("failed to reset enrichment status: %w", err)
+	}
+	_, err = tx.Exec(ctx,
+		"UPDATE enrichment_batches SET status = $2, total = $3 WHERE id = $1",
+		batch.ID, batch.Status, batch.Total,
+	)
+	if err != nil {
+		r.log.WithError(err).Errorf("Failed to update enrichment batch %d", batch.ID)
+		return fmt.Errorf("failed to update enrichment batch: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		r.log.WithError(err).Errorf("Failed to commit enrichment batch %d", batch.ID)
+		return fmt.Errorf("failed to commit enrichment batch: %w", err)
+	}
+	r.log.WithFields(logrus.Fields{
+		"batch_id": batch.ID,
+		"total":    batch.Total,
+	}).Info("Enqueued enrichment batch")
+	return nil
+}
+
+func (r *EnrichmentJobRepository) FailBatch(ctx context.Context, id int64, reason string) error {
+	if _, err := r.db.Exec(ctx, "UPDATE enrichment_batches SET status = 'failed', error = $2 WHERE id = $1", id, reason); err != nil {
+		r.log.WithError(err).Errorf("Failed to mark enrichment batch %d as failed", id)
+		return fmt.Errorf("failed to mark enrichment batch as failed: %w", err)
+	}
+	return nil
+}
+
+func (r *EnrichmentJobRepository) GetBatch(ctx context.Context, id int64) (*domain.EnrichmentBatch, error) {
+	query := `
+		SELECT b.id, b.filters, b.fields, b.force, b.status, b.total, b.error, b.created_at,
+		       COUNT(j.id) FILTER (WHERE j.status = 'queued'),
+		       COUNT(j.id) FILTER (WHERE j.status = 'running'),
+		       COUNT(j.id) FILTER (WHERE j.status = 'done'),
+		       COUNT(j.id) FILTER (WHERE j.status = 'failed')
+		FROM enrichment_batches b
+		LEFT JOIN enrichment_jobs j ON j.batch_id = b.id
+		WHERE b.id = $1
+		GROUP BY b.id
+	`
+	batch := &domain.EnrichmentBatch{}
+	var filters []byte
+	err := r.db.QueryRow(ctx, query, id).Scan(
+		&batch.ID, &filters, &batch.Fields, &batch.Force, &batch.Status, &batch.Total, &batch.Error, &batch.CreatedAt,
+		&batch.Queued, &batch.Running, &batch.Done, &batch.Failed,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		r.log.WithError(err).Errorf("Failed to get enrichment batch %d", id)
+		return nil, fmt.Errorf("failed to get enrichment batch: %w", err)
+	}
+	if err := json.Unmarshal(filters, &batch.Filters); err != nil {
+		return nil, fmt.Errorf("failed to decode batch filters: %w", err)
+	}
+	if batch.Status == domain.BatchRunning && batch.Queued+batch.Running == 0 {
+		batch.Status = domain.BatchDone
+	}
+	return batch, nil
+}
@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"fmt"
+	"person-service/internal/domain"
+	"strings"
+)
+
+// conditionBuilder collects WHERE conditions together with their arguments.
+// Placeholders are numbered from next on, so the conditions can follow other parameters.
+type conditionBuilder struct {
+	conditions []string
+	args       []interface{}
+	next       int
+}
+
+// arg registers value and returns its placeholder
+func (b *conditionBuilder) arg(value interface{}) string {
+	b.args = append(b.args, value)
+	placeholder := fmt.Sprintf("$%d", b.next)
+	b.next++
+	return placeholder
+}
+
+// add ANDs parts into a single condition. Negation treats unknown (NULL) results as false first,
+// so that "not male" also matches people without a gender.
+func (b *conditionBuilder) add(parts []string, not bool) {
+	if len(parts) == 0 {
+		return
+	}
+	condition := strings.Join(parts, " AND ")
+	if not {
+		condition = "NOT COALESCE(" + condition + ", false)"
+	} else if len(parts) > 1 {
+		condition = "(" + condition + ")"
+	}
+	b.conditions = append(b.conditions, condition)
+}
+
+// likePattern escapes the LIKE wildcards in value and wraps it for a substring match
+func likePattern(value string) string {
+	value = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+	return "%" + value + "%"
+}
+
+// stringFilter adds f for column. normalizedColumn, when not empty, holds the normalized form matched by f.Normalized.
+func (b *conditionBuilder) stringFilter(column, normalizedColumn string, f *domain.StringFilter) {
+	if f == nil {
+		return
+	}
+	var parts []string
+	if f.Eq != nil {
+		parts = append(parts, fmt.Sprintf("%s = %s", column, b.arg(*f.Eq)))
+	}
+	if f.Contains != nil {
+		part := fmt.Sprintf("%s ILIKE %s", column, b.arg(likePattern(*f.Contains)))
+		if normalizedColumn != "" && f.Normalized != nil {
+			part = fmt.Sprintf("(%s OR %s ILIKE %s)", part, normalizedColumn, b.arg(likePattern(*f.Normalized)))
+		}
+		parts = append(parts, part)
+	}
+	if f.In != nil {
+		parts = append(parts, fmt.Sprintf("%s = ANY(%s)", column, b.arg(f.In)))
+	}
+	if f.Null != nil {
+		if *f.Null {
+			parts = append(parts, fmt.Sprintf("COALESCE(%s, '') = ''", column))
+		} else {
+			parts = append(parts, fmt.Sprintf("COALESCE(%s, '') <> ''", column))
+		}
+	}
+	b.add(parts, f.Not)
+}
+
+func (b *conditionBuilder) intFilter(column string, f *domain.IntFilter) {
+	if f == nil {
+		return
+	}
+	var parts []string
+	if f.Eq != nil {
+		parts = append(parts, fmt.Sprintf("%s = %s", column, b.arg(*f.Eq)))
+	}
+	if f.Min != nil {
+		parts = append(parts, fmt.Sprintf("%s >= %s", column, b.arg(*f.Min)))
+	}
+	if f.Max != nil {
+		parts = append(parts, fmt.Sprintf("%s <= %s", column, b.arg(*f.Max)))
+	}
+	if f.In != nil {
+		parts = append(parts, fmt.Sprintf("%s = ANY(%s)", column, b.arg(f.In)))
+	}
+	if f.Null != nil {
+		if *f.Null {
+			parts = append(parts, fmt.Sprintf("COALESCE(%s, 0) = 0", column))
+		} else {
+			parts = append(parts, fmt.Sprintf("COALESCE(%s, 0) <> 0", column))
+		}
+	}
+	b.add(parts, f.Not)
+}
+
+func (b *conditionBuilder) floatFilter(column string, f *domain.FloatFilter) {
+	if f == nil {
+		return
+	}
+	var parts []string
+	if f.Min != nil {
+		parts = append(parts, fmt.Sprintf("%s >= %s", column, b.arg(*f.Min)))
+	}
+	if f.Max != nil {
+		parts = append(parts, fmt.Sprintf("%s <= %s", column, b.arg(*f.Max)))
+	}
+	if f.Null != nil {
+		if *f.Null {
+			parts = append(parts, column+" IS NULL")
+		} else {
+			parts = append(parts, column+" IS NOT NULL")
+		}
+	}
+	b.add(parts, f.Not)
+}
+
+func (b *conditionBuilder) timeFilter(column string, f *domain.TimeFilter) {
+	if f == nil {
+		return
+	}
+	var parts []string
+	if f.From != nil {
+		parts = append(parts, fmt.Sprintf("%s >= %s", column, b.arg(*f.From)))
+	}
+	if f.To != nil {
+		parts = append(parts, fmt.Sprintf("%s < %s", column, b.arg(*f.To)))
+	}
+	b.add(parts, f.Not)
+}
+
+// personConditions turns filter into WHERE conditions whose placeholders start at $firstArg.
+// Deleted people are left out unless the filter includes them.
+func personConditions(filter domain.PersonFilter, firstArg int) ([]string, []interface{}) {
+	b := &conditionBuilder{next: firstArg}
+	b.stringFilter("name", "name_normalized", filter.Name)
+	b.stringFilter("surname", "surname_normalized", filter.Surname)
+	b.stringFilter("patronymic", "patronymic_normalized", filter.Patronymic)
+	b.intFilter("age", filter.Age)
+	b.stringFilter("gender", "", filter.Gender)
+	b.stringFilter("nationality", "", filter.Nationality)
+	b.stringFilter("country_id", "", filter.CountryID)
+	b.stringFilter("enrichment_status", "", filter.EnrichmentStatus)
+	b.floatFilter("gender_probability", filter.GenderProbability)
+	b.floatFilter("nationality_probability", filter.NationalityProbability)
+	b.timeFilter("created_at", filter.CreatedAt)
+	if !filter.IncludeDeleted {
+		b.conditions = append(b.conditions, "deleted_at IS NULL")
+	}
+	return b.conditions, b.args
+}
+
+// personSortColumns maps the sortable fields to their sort expressions. Missing values sort as zero or
+// the empty string, the same way the expression indexes of migration 0013 are built.
+var personSortColumns = map[string]string{
+	domain.SortID:          "id",
+	domain.SortName:        "name",
+	domain.SortSurname:     "surname",
+	domain.SortAge:         "COALESCE(age, 0)",
+	domain.SortGender:      "COALESCE(gender, '')",
+	domain.SortNationality: "COALESCE(nationality, '')",
+	domain.SortCreatedAt:   "created_at",
+}
+
+// personOrderBy builds the ORDER BY list for sort, see domain.SortKeys. With reverse every direction
+// is flipped, which lists the rows before a cursor nearest first.
+func personOrderBy(sort []domain.SortOrder, reverse bool) string {
+	keys := domain.SortKeys(sort)
+	items := make([]string, 0, len(keys))
+	for _, key := range keys {
+		item := personSortColumns[key.Field]
+		if key.Desc != reverse {
+			item += " DESC"
+		}
+		items = append(items, item)
+	}
+	return strings.Join(items, ", ")
+}
+
+// keyset adds the condition selecting the rows after cursor in the order of its sort keys, or before it
+// for backward cursors. Keys that all share a direction are compared as a row, which the (key, id)
+// indexes serve directly; mixed directions expand into an OR of per-key comparisons.
+func (b *conditionBuilder) keyset(cursor *domain.Cursor) error {
+	sort, err := domain.ParseSort(cursor.Sort)
+	if err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidCursor, err)
+	}
+	values, err := cursor.KeyValues()
+	if err != nil {
+		return err
+	}
+	keys := domain.SortKeys(sort)
+	operator := func(desc bool) string {
+		if desc != cursor.Backward {
+			return "<"
+		}
+		return ">"
+	}
+
+	columns := make([]string, len(keys))
+	placeholders := make([]string, len(keys))
+	sameDirection := true
+	for i, key := range keys {
+		columns[i] = personSortColumns[key.Field]
+		placeholders[i] = b.arg(values[i])
+		sameDirection = sameDirection && key.Desc == keys[0].Desc
+	}
+	if sameDirection {
+		b.conditions = append(b.conditions, fmt.Sprintf("(%s) %s (%s)",
+			strings.Join(columns, ", "), operator(keys[0].Desc), strings.Join(placeholders, ", ")))
+		return nil
+	}
+
+	alternatives := make([]string, len(keys))
+	for i, key := range keys {
+		parts := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			parts = append(parts, fmt.Sprintf("%s = %s", columns[j], placeholders[j]))
+		}
+		parts = append(parts, fmt.Sprintf("%s %s %s", columns[i], operator(key.Desc), placeholders[i]))
+		alternatives[i] = strings.Join(parts, " AND ")
+		if len(parts) > 1 {
+			alternatives[i] = "(" + alternatives[i] + ")"
+		}
+	}
+	b.conditions = append(b.conditions, "("+strings.Join(alternatives, " OR ")+")")
+	return nil
+}
@@ -0,0 +1,250 @@
+package repository
+
+import (
+	"person-service/internal/domain"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func ptr[T any](value T) *T {
+	return &value
+}
+
+func TestPersonConditions(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		filter     domain.PersonFilter
+		firstArg   int
+		conditions []string
+		args       []interface{}
+	}{
+		{
+			name:     "empty filter",
+			filter:   domain.PersonFilter{},
+			firstArg: 1,
+		},
+		{
+			name:     "deleted people included",
+			filter:   domain.PersonFilter{IncludeDeleted: true},
+			firstArg: 1,
+		},
+		{
+			name:       "string equality",
+			filter:     domain.PersonFilter{Gender: domain.StringEq("male")},
+			firstArg:   1,
+			conditions: []string{"gender = $1"},
+			args:       []interface{}{"male"},
+		},
+		{
+			name:       "substring match escapes wildcards",
+			filter:     domain.PersonFilter{Surname: domain.StringContains("50%_off")},
+			firstArg:   1,
+			conditions: []string{"surname ILIKE $1"},
+			args:       []interface{}{`%50\%\_off%`},
+		},
+		{
+			name: "substring match on the normalized name",
+			filter: domain.PersonFilter{
+				Name: &domain.StringFilter{Contains: ptr("Дмитрий"), Normalized: ptr("dmitrii")},
+			},
+			firstArg:   1,
+			conditions: []string{"(name ILIKE $1 OR name_normalized ILIKE $2)"},
+			args:       []interface{}{"%Дмитрий%", "%dmitrii%"},
+		},
+		{
+			name: "normalized key is ignored for columns without a normalized form",
+			filter: domain.PersonFilter{
+				Nationality: &domain.StringFilter{Contains: ptr("RU"), Normalized: ptr("ru")},
+			},
+			firstArg:   1,
+			conditions: []string{"nationality ILIKE $1"},
+			args:       []interface{}{"%RU%"},
+		},
+		{
+			name:       "in-list",
+			filter:     domain.PersonFilter{Nationality: &domain.StringFilter{In: []string{"RU", "UA"}}},
+			firstArg:   1,
+			conditions: []string{"nationality = ANY($1)"},
+			args:       []interface{}{[]string{"RU", "UA"}},
+		},
+		{
+			name:       "negated in-list keeps rows without a value",
+			filter:     domain.PersonFilter{Gender: &domain.StringFilter{In: []string{"male"}, Not: true}},
+			firstArg:   1,
+			conditions: []string{"NOT COALESCE(gender = ANY($1), false)"},
+			args:       []interface{}{[]string{"male"}},
+		},
+		{
+			name: "null checks",
+			filter: domain.PersonFilter{
+				Patronymic:        &domain.StringFilter{Null: ptr(true)},
+				Age:               &domain.IntFilter{Null: ptr(false)},
+				GenderProbability: &domain.FloatFilter{Null: ptr(true)},
+			},
+			firstArg: 1,
+			conditions: []string{
+				"COALESCE(patronymic, '') = ''",
+				"COALESCE(age, 0) <> 0",
+				"gender_probability IS NULL",
+			},
+		},
+		{
+			name:       "integer range",
+			filter:     domain.PersonFilter{Age: &domain.IntFilter{Min: ptr(18), Max: ptr(65)}},
+			firstArg:   1,
+			conditions: []string{"(age >= $1 AND age <= $2)"},
+			args:       []interface{}{18, 65},
+		},
+		{
+			name:       "negated integer range",
+			filter:     domain.PersonFilter{Age: &domain.IntFilter{Min: ptr(18), Max: ptr(65), Not: true}},
+			firstArg:   1,
+			conditions: []string{"NOT COALESCE(age >= $1 AND age <= $2, false)"},
+			args:       []interface{}{18, 65},
+		},
+		{
+			name:       "integer equality and in-list",
+			filter:     domain.PersonFilter{Age: &domain.IntFilter{Eq: ptr(30), In: []int{30, 31}}},
+			firstArg:   1,
+			conditions: []string{"(age = $1 AND age = ANY($2))"},
+			args:       []interface{}{30, []int{30, 31}},
+		},
+		{
+			name:       "probability bounds",
+			filter:     domain.PersonFilter{NationalityProbability: &domain.FloatFilter{Min: ptr(0.5), Max: ptr(0.9)}},
+			firstArg:   1,
+			conditions: []string{"(nationality_probability >= $1 AND nationality_probability <= $2)"},
+			args:       []interface{}{0.5, 0.9},
+		},
+		{
+			name:       "half-open time range",
+			filter:     domain.PersonFilter{CreatedAt: &domain.TimeFilter{From: &from, To: &to}},
+			firstArg:   1,
+			conditions: []string{"(created_at >= $1 AND created_at < $2)"},
+			args:       []interface{}{from, to},
+		},
+		{
+			name: "placeholders continue after earlier parameters",
+			filter: domain.PersonFilter{
+				Surname:          domain.StringContains("ivanov"),
+				Age:              &domain.IntFilter{Eq: ptr(30)},
+				EnrichmentStatus: domain.StringEq(domain.EnrichmentComplete),
+			},
+			firstArg:   5,
+			conditions: []string{"surname ILIKE $5", "age = $6", "enrichment_status = $7"},
+			args:       []interface{}{"%ivanov%", 30, domain.EnrichmentComplete},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conditions, args := personConditions(tt.filter, tt.firstArg)
+			want := tt.conditions
+			if !tt.filter.IncludeDeleted {
+				// deleted people are left out after the filter's own conditions
+				want = append(slices.Clone(want), "deleted_at IS NULL")
+			}
+			if strings.Join(conditions, " AND ") != strings.Join(want, " AND ") {
+				t.Errorf("conditions = %q, want %q", conditions, want)
+			}
+			if len(args) != len(tt.args) || (len(args) > 0 && !reflect.DeepEqual(args, tt.args)) {
+				t.Errorf("args = %#v, want %#v", args, tt.args)
+			}
+		})
+	}
+}
+
+func TestPersonOrderBy(t *testing.T) {
+	tests := []struct {
+		name    string
+		sort    []domain.SortOrder
+		reverse bool
+		want    string
+	}{
+		{
+			name: "default",
+			want: "created_at DESC, id DESC",
+		},
+		{
+			name: "mixed directions with tiebreaker in the last direction",
+			sort: []domain.SortOrder{{Field: domain.SortSurname}, {Field: domain.SortAge, Desc: true}},
+			want: "surname, COALESCE(age, 0) DESC, id DESC",
+		},
+		{
+			name: "explicit id is not repeated",
+			sort: []domain.SortOrder{{Field: domain.SortID, Desc: true}, {Field: domain.SortName}},
+			want: "id DESC, name",
+		},
+		{
+			name:    "reversed for backward cursors",
+			sort:    []domain.SortOrder{{Field: domain.SortSurname}, {Field: domain.SortAge, Desc: true}},
+			reverse: true,
+			want:    "surname DESC, COALESCE(age, 0), id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := personOrderBy(tt.sort, tt.reverse); got != tt.want {
+				t.Errorf("personOrderBy() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyset(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 30, 0, 123456000, time.UTC)
+	person := &domain.Person{ID: 42, Surname: "Ivanov", Age: 30, CreatedAt: createdAt}
+
+	tests := []struct {
+		name      string
+		sort      []domain.SortOrder
+		backward  bool
+		condition string
+		args      []interface{}
+	}{
+		{
+			name:      "default sort compares rows",
+			condition: "(created_at, id) < ($3, $4)",
+			args:      []interface{}{createdAt, int64(42)},
+		},
+		{
+			name:      "backward flips the comparison",
+			sort:      []domain.SortOrder{{Field: domain.SortSurname}},
+			backward:  true,
+			condition: "(surname, id) < ($3, $4)",
+			args:      []interface{}{"Ivanov", int64(42)},
+		},
+		{
+			name:      "mixed directions expand per key",
+			sort:      []domain.SortOrder{{Field: domain.SortSurname}, {Field: domain.SortAge, Desc: true}},
+			condition: "(surname > $3 OR (surname = $3 AND COALESCE(age, 0) < $4) OR (surname = $3 AND COALESCE(age, 0) = $4 AND id < $5))",
+			args:      []interface{}{"Ivanov", 30, int64(42)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, err := domain.DecodeCursor(domain.NewCursor(tt.sort, person, tt.backward).Encode())
+			if err != nil {
+				t.Fatalf("DecodeCursor() error = %v", err)
+			}
+			b := &conditionBuilder{next: 3}
+			if err := b.keyset(cursor); err != nil {
+				t.Fatalf("keyset() error = %v", err)
+			}
+			if len(b.conditions) != 1 || b.conditions[0] != tt.condition {
+				t.Errorf("conditions = %q, want %q", b.conditions, tt.condition)
+			}
+			if !reflect.DeepEqual(b.args, tt.args) {
+				t.Errorf("args = %#v, want %#v", b.args, tt.args)
+			}
+		})
+	}
+}
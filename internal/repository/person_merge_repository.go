@@ -0,0 +1,341 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+	"person-service/internal/domain"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type PersonMergeRepositoryInterface interface {
+	// FindDuplicates returns pairs of people matching each other, most similar first
+	FindDuplicates(ctx context.Context, match domain.DuplicateMatch) ([]*domain.DuplicatePair, error)
+	// Merge takes the fields picked by domain.MergeFields over from the duplicate into the survivor,
+	// records the merge and marks the duplicate deleted, all in one transaction
+	Merge(ctx context.Context, survivorID, duplicateID int64) (*domain.PersonMerge, error)
+	// GetSurvivorID returns the person id was merged into, following merges of the survivor itself.
+	// Returns ErrNotFound if id was not merged or its merge was undone.
+	GetSurvivorID(ctx context.Context, id int64) (int64, error)
+	// ListBySurvivor returns the merges into the person and into the people merged into it, newest first.
+	// Undone merges are included.
+	ListBySurvivor(ctx context.Context, survivorID int64) ([]*domain.PersonMerge, error)
+}
+
+type PersonMergeRepository struct {
+	db     *pgxpool.Pool
+	people *PersonRepository
+	log    *logrus.Logger
+}
+
+func NewPersonMergeRepository(db *pgxpool.Pool, log *logrus.Logger) PersonMergeRepositoryInterface {
+	return &PersonMergeRepository{
+		db:     db,
+		people: &PersonRepository{db: db, log: log},
+		log:    log,
+	}
+}
+
+// duplicateColumns maps the match fields to their normalized columns
+var duplicateColumns = map[string]string{
+	domain.MatchName:       "name_normalized",
+	domain.MatchSurname:    "surname_normalized",
+	domain.MatchPatronymic: "patronymic_normalized",
+}
+
+// mergeColumns lists the columns taken over from the duplicate with each merged field
+var mergeColumns = map[string][]string{
+	domain.FieldPatronymic:  {"patronymic", "patronymic_normalized"},
+	domain.FieldCountryID:   {"country_id"},
+	domain.FieldAge:         {"age", "age_localization"},
+	domain.FieldGender:      {"gender", "gender_probability", "gender_count", "gender_localization", "gender_source"},
+	domain.FieldNationality: {"nationality", "nationality_probability"},
+}
+
+// duplicateConditions builds the join conditions of a pair a, b and the expression of its similarity.
+// Similar matches use the pg_trgm % operator, whose threshold is set for the transaction.
+// A missing patronymic only matches when name or surname are matched as well, otherwise it would match anyone.
+func duplicateConditions(match domain.DuplicateMatch) ([]string, string) {
+	exact := match.MinSimilarity >= 1
+	names := slices.Contains(match.Fields, domain.MatchName) || slices.Contains(match.Fields, domain.MatchSurname)
+	var conditions, scores []string
+	for _, field := range match.Fields {
+		column, ok := duplicateColumns[field]
+		if !ok {
+			continue
+		}
+		a, b := "a."+column, "b."+column
+		condition := a + " = " + b
+		if !exact {
+			condition = a + " % " + b
+			scores = append(scores, fmt.Sprintf("COALESCE(similarity(%s, %s), 1)", a, b))
+		}
+		if field == domain.MatchPatronymic && names {
+			condition = fmt.Sprintf("(%s IS NULL OR %s IS NULL OR %s)", a, b, condition)
+		}
+		conditions = append(conditions, condition)
+	}
+	score := "1"
+	if len(scores) == 1 {
+		score = scores[0]
+	} else if len(scores) > 1 {
+		score = "LEAST(" + strings.Join(scores, ", ") + ")"
+	}
+	return conditions, score
+}
+
+func (r *PersonMergeRepository) FindDuplicates(ctx context.Context, match domain.DuplicateMatch) ([]*domain.DuplicatePair, error) {
+	conditions, score := duplicateConditions(match)
+	if len(conditions) == 0 {
+		return nil, fmt.Errorf("no fields to match duplicates on")
+	}
+	query := fmt.Sprintf(`
+		SELECT a.id, b.id, %s AS similarity
+		FROM people a
+		JOIN people b ON b.id > a.id AND b.deleted_at IS NULL AND %s
+		WHERE a.deleted_at IS NULL
+		ORDER BY similarity DESC, a.id, b.id
+		LIMIT $1 OFFSET $2
+	`, score, strings.Join(conditions, " AND "))
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		r.log.WithError(err).Error("Failed to begin transaction")
+		return nil, fmt.Errorf("failed to find duplicates: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	threshold := strconv.FormatFloat(match.MinSimilarity, 'f', -1, 64)
+	if _, err := tx.Exec(ctx, "SELECT set_config('pg_trgm.similarity_threshold', $1, true)", threshold); err != nil {
+		r.log.WithError(err).Error("Failed to set the similarity threshold")
+		return nil, fmt.Errorf("failed to find duplicates: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, query, match.Limit, match.Offset)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"query": query,
+			"error": err,
+		}).Error("Failed to find duplicates")
+		return nil, fmt.Errorf("failed to find duplicates: %w", err)
+	}
+	defer rows.Close()
+
+	type pair struct {
+		personID, duplicateID int64
+		similarity            float64
+	}
+	var pairs []pair
+	var ids []int64
+	for rows.Next() {
+		var p pair
+		if err := rows.Scan(&p.personID, &p.duplicateID, &p.similarity); err != nil {
+			r.log.WithError(err).Error("Failed to scan duplicate pair")
+			return nil, fmt.Errorf("failed to scan duplicate pair: %w", err)
+		}
+		pairs = append(pairs, p)
+		ids = append(ids, p.personID, p.duplicateID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	rows.Close()
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to find duplicates: %w", err)
+	}
+
+	people, err := r.people.getByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*domain.DuplicatePair, 0, len(pairs))
+	for _, p := range pairs {
+		person, duplicate := people[p.personID], people[p.duplicateID]
+		if person == nil || duplicate == nil {
+			// deleted or merged in the meantime
+			continue
+		}
+		result = append(result, &domain.DuplicatePair{Person: person, Duplicate: duplicate, Similarity: p.similarity})
+	}
+	return result, nil
+}
+
+func (r *PersonMergeRepository) Merge(ctx context.Context, survivorID, duplicateID int64) (*domain.PersonMerge, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		r.log.WithError(err).Error("Failed to begin transaction")
+		return nil, fmt.Errorf("failed to merge people: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock both rows in id order, so that concurrent merges of the same people cannot deadlock
+	rows, err := tx.Query(ctx, "SELECT id FROM people WHERE id = ANY($1) AND deleted_at IS NULL ORDER BY id FOR UPDATE", []int64{survivorID, duplicateID})
+	if err != nil {
+		r.log.WithError(err).Error("Failed to lock people")
+		return nil, fmt.Errorf("failed to merge people: %w", err)
+	}
+	locked := 0
+	for rows.Next() {
+		locked++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to merge people: %w", err)
+	}
+	if locked != 2 {
+		return nil, ErrNotFound
+	}
+
+	people, err := r.people.getByIDs(ctx, []int64{survivorID, duplicateID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge people: %w", err)
+	}
+	survivor, duplicate := people[survivorID], people[duplicateID]
+	if survivor == nil || duplicate == nil {
+		return nil, ErrNotFound
+	}
+	fields := domain.MergeFields(survivor, duplicate)
+
+	var sets []string
+	var enriched []string
+	for _, field := range fields {
+		for _, column := range mergeColumns[field] {
+			sets = append(sets, fmt.Sprintf("%s = d.%s", column, column))
+		}
+		if field == domain.FieldAge || field == domain.FieldGender || field == domain.FieldNationality {
+			enriched = append(enriched, field)
+		}
+	}
+	if len(sets) > 0 {
+		query := "UPDATE people s SET " + strings.Join(sets, ", ") + " FROM people d WHERE s.id = $1 AND d.id = $2"
+		if _, err := tx.Exec(ctx, query, survivorID, duplicateID); err != nil {
+			r.log.WithError(err).Errorf("Failed to merge person %d into %d", duplicateID, survivorID)
+			return nil, fmt.Errorf("failed to merge people: %w", err)
+		}
+	}
+	if len(enriched) > 0 {
+		if _, err := tx.Exec(ctx, "DELETE FROM person_field_provenance WHERE person_id = $1 AND field = ANY($2)", survivorID, enriched); err != nil {
+			return nil, fmt.Errorf("failed to merge provenance: %w", err)
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO person_field_provenance (person_id, field, source, confidence, fetched_at)
+			SELECT $1, field, source, confidence, fetched_at FROM person_field_provenance WHERE person_id = $2 AND field = ANY($3)`,
+			survivorID, duplicateID, enriched,
+		); err != nil {
+			return nil, fmt.Errorf("failed to merge provenance: %w", err)
+		}
+	}
+	for _, field := range fields {
+		if field != domain.FieldNationality {
+			continue
+		}
+		if _, err := tx.Exec(ctx, "DELETE FROM person_nationalities WHERE person_id = $1", survivorID); err != nil {
+			return nil, fmt.Errorf("failed to merge nationalities: %w", err)
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO person_nationalities (person_id, rank, country_id, probability)
+			SELECT $1, rank, country_id, probability FROM person_nationalities WHERE person_id = $2`,
+			survivorID, duplicateID,
+		); err != nil {
+			return nil, fmt.Errorf("failed to merge nationalities: %w", err)
+		}
+	}
+
+	snapshot, err := json.Marshal(duplicate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode merged person: %w", err)
+	}
+	merge := &domain.PersonMerge{SurvivorID: survivorID, MergedID: duplicateID, Fields: fields, Merged: duplicate}
+	if merge.Fields == nil {
+		merge.Fields = []string{}
+	}
+	if err := tx.QueryRow(ctx,
+		"INSERT INTO person_merges (survivor_id, merged_id, fields, merged) VALUES ($1, $2, $3, $4) RETURNING id, merged_at",
+		survivorID, duplicateID, merge.Fields, snapshot,
+	).Scan(&merge.ID, &merge.MergedAt); err != nil {
+		r.log.WithError(err).Error("Failed to record merge")
+		return nil, fmt.Errorf("failed to record merge: %w", err)
+	}
+	// The duplicate is deleted softly: restoring it undoes the merge, and the purge removes it for good
+	if _, err := tx.Exec(ctx, "UPDATE people SET deleted_at = CURRENT_TIMESTAMP WHERE id = $1", duplicateID); err != nil {
+		r.log.WithError(err).Errorf("Failed to delete merged person %d", duplicateID)
+		return nil, fmt.Errorf("failed to delete merged person: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		r.log.WithError(err).Error("Failed to commit merge")
+		return nil, fmt.Errorf("failed to merge people: %w", err)
+	}
+	r.log.WithFields(logrus.Fields{
+		"survivor_id": survivorID,
+		"merged_id":   duplicateID,
+		"fields":      fields,
+	}).Info("Merged people")
+	return merge, nil
+}
+
+func (r *PersonMergeRepository) GetSurvivorID(ctx context.Context, id int64) (int64, error) {
+	// People merged into the duplicate earlier keep their row, so that restoring the duplicate leads them back to it
+	query := `
+		WITH RECURSIVE chain AS (
+			SELECT survivor_id, 1 AS depth FROM person_merges WHERE merged_id = $1 AND undone_at IS NULL
+			UNION ALL
+			SELECT m.survivor_id, c.depth + 1
+			FROM person_merges m
+			JOIN chain c ON m.merged_id = c.survivor_id AND m.undone_at IS NULL
+		)
+		SELECT survivor_id FROM chain ORDER BY depth DESC LIMIT 1
+	`
+	var survivorID int64
+	err := r.db.QueryRow(ctx, query, id).Scan(&survivorID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrNotFound
+		}
+		r.log.WithError(err).Errorf("Failed to look up merge of person %d", id)
+		return 0, fmt.Errorf("failed to get merge: %w", err)
+	}
+	return survivorID, nil
+}
+
+func (r *PersonMergeRepository) ListBySurvivor(ctx context.Context, survivorID int64) ([]*domain.PersonMerge, error) {
+	query := `
+		WITH RECURSIVE merges AS (
+			SELECT * FROM person_merges WHERE survivor_id = $1
+			UNION
+			SELECT m.*
+			FROM person_merges m
+			JOIN merges p ON m.survivor_id = p.merged_id AND p.undone_at IS NULL
+		)
+		SELECT id, survivor_id, merged_id, fields, merged, merged_at, undone_at
+		FROM merges
+		ORDER BY merged_at DESC, id DESC
+	`
+	rows, err := r.db.Query(ctx, query, survivorID)
+	if err != nil {
+		r.log.WithError(err).Errorf("Failed to list merges into person %d", survivorID)
+		return nil, fmt.Errorf("failed to list merges: %w", err)
+	}
+	defer rows.Close()
+
+	merges := []*domain.PersonMerge{}
+	for rows.Next() {
+		merge := &domain.PersonMerge{}
+		var snapshot []byte
+		if err := rows.Scan(&merge.ID, &merge.SurvivorID, &merge.MergedID, &merge.Fields, &snapshot, &merge.MergedAt, &merge.UndoneAt); err != nil {
+			r.log.WithError(err).Error("Failed to scan merge")
+			return nil, fmt.Errorf("failed to scan merge: %w", err)
+		}
+		if err := json.Unmarshal(snapshot, &merge.Merged); err != nil {
+			return nil, fmt.Errorf("failed to decode merged person: %w", err)
+		}
+		merges = append(merges, merge)
+	}
+	return merges, rows.Err()
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"person-service/internal/domain"
+	"reflect"
+	"testing"
+)
+
+func TestDuplicateConditions(t *testing.T) {
+	tests := []struct {
+		name       string
+		match      domain.DuplicateMatch
+		conditions []string
+		score      string
+	}{
+		{
+			name:  "exact names",
+			match: domain.DuplicateMatch{Fields: []string{domain.MatchName, domain.MatchSurname}, MinSimilarity: 1},
+			conditions: []string{
+				"a.name_normalized = b.name_normalized",
+				"a.surname_normalized = b.surname_normalized",
+			},
+			score: "1",
+		},
+		{
+			name:  "similar names",
+			match: domain.DuplicateMatch{Fields: []string{domain.MatchName, domain.MatchSurname}, MinSimilarity: 0.6},
+			conditions: []string{
+				"a.name_normalized % b.name_normalized",
+				"a.surname_normalized % b.surname_normalized",
+			},
+			score: "LEAST(COALESCE(similarity(a.name_normalized, b.name_normalized), 1), " +
+				"COALESCE(similarity(a.surname_normalized, b.surname_normalized), 1))",
+		},
+		{
+			name:  "missing patronymic matches along with the surname",
+			match: domain.DuplicateMatch{Fields: []string{domain.MatchSurname, domain.MatchPatronymic}, MinSimilarity: 1},
+			conditions: []string{
+				"a.surname_normalized = b.surname_normalized",
+				"(a.patronymic_normalized IS NULL OR b.patronymic_normalized IS NULL OR a.patronymic_normalized = b.patronymic_normalized)",
+			},
+			score: "1",
+		},
+		{
+			name:       "patronymic alone requires both patronymics",
+			match:      domain.DuplicateMatch{Fields: []string{domain.MatchPatronymic}, MinSimilarity: 0.6},
+			conditions: []string{"a.patronymic_normalized % b.patronymic_normalized"},
+			score:      "COALESCE(similarity(a.patronymic_normalized, b.patronymic_normalized), 1)",
+		},
+		{
+			name:  "unknown fields are skipped",
+			match: domain.DuplicateMatch{Fields: []string{"age"}, MinSimilarity: 1},
+			score: "1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conditions, score := duplicateConditions(tt.match)
+			if !reflect.DeepEqual(conditions, tt.conditions) {
+				t.Errorf("conditions = %q, want %q", conditions, tt.conditions)
+			}
+			if score != tt.score {
+				t.Errorf("score = %q, want %q", score, tt.score)
+			}
+		})
+	}
+}
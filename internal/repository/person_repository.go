@@ -3,25 +3,50 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/sirupsen/logrus"
 	"person-service/internal/domain"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 )
 
 var (
 	ErrNotFound = errors.New("not found")
+	// ErrNotDeleted is returned when restoring a person that is not deleted
+	ErrNotDeleted = errors.New("not deleted")
 )
 
 type PersonRepositoryInterface interface {
 	Create(ctx context.Context, person *domain.Person) (int64, error)
-	GetById(ctx context.Context, id int64) (*domain.Person, error)
-	GetAll(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]*domain.Person, int, error)
+	// CreateMany stores the names, country hint, known values and enrichment status of people in one transaction
+	// and returns their IDs in the same order
+	CreateMany(ctx context.Context, people []*domain.Person) ([]int64, error)
+	// GetById returns the person; deleted people only with includeDeleted
+	GetById(ctx context.Context, id int64, includeDeleted bool) (*domain.Person, error)
+	// GetAll returns a page of the people matching the query's filter in sort order, and the number of all matching people
+	GetAll(ctx context.Context, query domain.PersonQuery) (*domain.PersonPage, error)
+	// Search returns the people whose names match the search best, best match first
+	Search(ctx context.Context, search domain.PersonSearch) ([]*domain.PersonSearchResult, error)
 	Update(ctx context.Context, id int64, person *domain.Person) error
+	// FillEnrichment stores the non-empty enriched fields of patch listed in fields, with their provenance.
+	// Unless overwrite is set only fields that are still empty on the stored person change.
+	// Fields set manually are left alone unless force is set. Deleted people give ErrNotFound.
+	FillEnrichment(ctx context.Context, id int64, patch *domain.Person, fields []string, overwrite, force bool) error
+	// SetEnrichmentStatus gives ErrNotFound for deleted people, like FillEnrichment
+	SetEnrichmentStatus(ctx context.Context, id int64, status string) error
+	// Delete marks the person as deleted. Deleted people are left out everywhere and purged later.
 	Delete(ctx context.Context, id int64) error
+	// Restore clears the deletion mark of the person. The merge of a merged person is marked undone.
+	// Returns ErrNotDeleted for a person that is not deleted.
+	Restore(ctx context.Context, id int64) error
+	// Purge removes the people deleted before the given time for good and returns their number
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
 }
 
 type PersonRepository struct {
@@ -37,33 +62,225 @@ func NewPersonRepository(db *pgxpool.Pool, log *logrus.Logger) PersonRepositoryI
 }
 
 func (r *PersonRepository) Create(ctx context.Context, person *domain.Person) (int64, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logrus.Errorf("Failed to begin transaction: %v", err)
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
-		INSERT INTO people (name, surname, patronymic, age, gender, nationality)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO people (name, surname, patronymic, name_normalized, surname_normalized, patronymic_normalized, country_id,
+		                    age, age_localization, gender, gender_probability, gender_count, gender_localization, gender_source, nationality, nationality_probability, enrichment_status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
 		RETURNING id
     `
 	var id int64
-	err := r.db.QueryRow(ctx, query,
+	err = tx.QueryRow(ctx, query,
 		person.Name,
 		person.Surname,
 		person.Patronymic,
+		person.NameNormalized,
+		person.SurnameNormalized,
+		person.PatronymicNormalized,
+		person.CountryID,
 		person.Age,
+		person.AgeLocalization,
 		person.Gender,
+		person.GenderProbability,
+		person.GenderCount,
+		person.GenderLocalization,
+		person.GenderSource,
 		person.Nationality,
+		person.NationalityProbability,
+		person.EnrichmentStatus,
 	).Scan(&id)
 	if err != nil {
 		logrus.Errorf("Failed to create person with name %s: %v", person.Name, err)
 		return 0, err
 	}
+
+	if err := insertNationalities(ctx, tx, id, person.Nationalities); err != nil {
+		logrus.Errorf("Failed to store nationalities of person %d: %v", id, err)
+		return 0, err
+	}
+	for field, provenance := range person.Provenance {
+		if err := upsertProvenance(ctx, tx, id, field, provenance); err != nil {
+			logrus.Errorf("Failed to store provenance of %s of person %d: %v", field, id, err)
+			return 0, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		logrus.Errorf("Failed to commit person with name %s: %v", person.Name, err)
+		return 0, err
+	}
 	logrus.Debugf("Created person with ID: %d", id)
 	return id, nil
 }
 
-func (r *PersonRepository) GetById(ctx context.Context, id int64) (*domain.Person, error) {
-	query := "SELECT id, name, surname, patronymic, age, gender, nationality, created_at FROM people WHERE id = $1"
+func (r *PersonRepository) CreateMany(ctx context.Context, people []*domain.Person) ([]int64, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logrus.Errorf("Failed to begin transaction: %v", err)
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	batch := &pgx.Batch{}
+	for _, person := range people {
+		batch.Queue(
+			`INSERT INTO people (name, surname, patronymic, name_normalized, surname_normalized, patronymic_normalized, country_id,
+			                     age, gender, nationality, enrichment_status)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id`,
+			person.Name, person.Surname, person.Patronymic, person.NameNormalized, person.SurnameNormalized, person.PatronymicNormalized,
+			person.CountryID, person.Age, person.Gender, person.Nationality, person.EnrichmentStatus,
+		)
+	}
+	results := tx.SendBatch(ctx, batch)
+	ids := make([]int64, len(people))
+	for i, person := range people {
+		if err := results.QueryRow().Scan(&ids[i]); err != nil {
+			results.Close()
+			logrus.Errorf("Failed to create person with name %s: %v", person.Name, err)
+			return nil, err
+		}
+	}
+	if err := results.Close(); err != nil {
+		logrus.Errorf("Failed to create people: %v", err)
+		return nil, err
+	}
+
+	provenance := &pgx.Batch{}
+	for i, person := range people {
+		for field, p := range person.Provenance {
+			provenance.Queue(
+				"INSERT INTO person_field_provenance (person_id, field, source, confidence, fetched_at) VALUES ($1, $2, $3, $4, $5)",
+				ids[i], field, p.Source, p.Confidence, p.FetchedAt,
+			)
+		}
+	}
+	if provenance.Len() > 0 {
+		if err := tx.SendBatch(ctx, provenance).Close(); err != nil {
+			logrus.Errorf("Failed to store provenance of %d people: %v", len(people), err)
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logrus.Errorf("Failed to commit %d people: %v", len(people), err)
+		return nil, err
+	}
+	logrus.Debugf("Created %d people", len(ids))
+	return ids, nil
+}
+
+func insertNationalities(ctx context.Context, tx pgx.Tx, personID int64, countries []domain.CountryProbability) error {
+	for i, country := range countries {
+		_, err := tx.Exec(ctx,
+			"INSERT INTO person_nationalities (person_id, rank, country_id, probability) VALUES ($1, $2, $3, $4)",
+			personID, i+1, country.CountryID, country.Probability,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert nationality %s: %w", country.CountryID, err)
+		}
+	}
+	return nil
+}
+
+// loadNationalities fills the ranked nationality distribution of people with a single query
+func (r *PersonRepository) loadNationalities(ctx context.Context, people []*domain.Person) error {
+	if len(people) == 0 {
+		return nil
+	}
+	byID := make(map[int64]*domain.Person, len(people))
+	ids := make([]int64, 0, len(people))
+	for _, person := range people {
+		byID[person.ID] = person
+		ids = append(ids, person.ID)
+	}
+
+	rows, err := r.db.Query(ctx,
+		"SELECT person_id, country_id, probability FROM person_nationalities WHERE person_id = ANY($1) ORDER BY person_id, rank",
+		ids,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to get nationalities: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var personID int64
+		var country domain.CountryProbability
+		if err := rows.Scan(&personID, &country.CountryID, &country.Probability); err != nil {
+			return fmt.Errorf("failed to scan nationality: %w", err)
+		}
+		if person, ok := byID[personID]; ok {
+			person.Nationalities = append(person.Nationalities, country)
+		}
+	}
+	return rows.Err()
+}
+
+func upsertProvenance(ctx context.Context, tx pgx.Tx, personID int64, field string, provenance *domain.FieldProvenance) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO person_field_provenance (person_id, field, source, confidence, fetched_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (person_id, field) DO UPDATE
+		SET source = EXCLUDED.source, confidence = EXCLUDED.confidence, fetched_at = EXCLUDED.fetched_at
+	`, personID, field, provenance.Source, provenance.Confidence, provenance.FetchedAt)
+	return err
+}
+
+// loadProvenance fills the per-field provenance of people with a single query
+func (r *PersonRepository) loadProvenance(ctx context.Context, people []*domain.Person) error {
+	if len(people) == 0 {
+		return nil
+	}
+	byID := make(map[int64]*domain.Person, len(people))
+	ids := make([]int64, 0, len(people))
+	for _, person := range people {
+		byID[person.ID] = person
+		ids = append(ids, person.ID)
+	}
+
+	rows, err := r.db.Query(ctx,
+		"SELECT person_id, field, source, confidence, fetched_at FROM person_field_provenance WHERE person_id = ANY($1)",
+		ids,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to get provenance: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var personID int64
+		var field string
+		provenance := &domain.FieldProvenance{}
+		if err := rows.Scan(&personID, &field, &provenance.Source, &provenance.Confidence, &provenance.FetchedAt); err != nil {
+			return fmt.Errorf("failed to scan provenance: %w", err)
+		}
+		if person, ok := byID[personID]; ok {
+			if person.Provenance == nil {
+				person.Provenance = make(map[string]*domain.FieldProvenance)
+			}
+			person.Provenance[field] = provenance
+		}
+	}
+	return rows.Err()
+}
+
+func (r *PersonRepository) GetById(ctx context.Context, id int64, includeDeleted bool) (*domain.Person, error) {
+	query := `
+		SELECT id, name, surname, patronymic, name_normalized, surname_normalized, patronymic_normalized, country_id, age, age_localization, gender, gender_probability, gender_count, gender_localization, gender_source,
+		       nationality, nationality_probability, enrichment_status, created_at, deleted_at
+		FROM people WHERE id = $1 AND ($2 OR deleted_at IS NULL)
+	`
 	person := &domain.Person{}
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&person.ID, &person.Name, &person.Surname, &person.Patronymic, &person.Age, &person.Gender, &person.Nationality, &person.CreatedAt,
+	err := r.db.QueryRow(ctx, query, id, includeDeleted).Scan(
+		&person.ID, &person.Name, &person.Surname, &person.Patronymic,
+		&person.NameNormalized, &person.SurnameNormalized, &person.PatronymicNormalized, &person.CountryID, &person.Age, &person.AgeLocalization, &person.Gender,
+		&person.GenderProbability, &person.GenderCount, &person.GenderLocalization, &person.GenderSource, &person.Nationality, &person.NationalityProbability,
+		&person.EnrichmentStatus, &person.CreatedAt, &person.DeletedAt,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -72,67 +289,95 @@ func (r *PersonRepository) GetById(ctx context.Context, id int64) (*domain.Perso
 		logrus.WithError(err).Errorf("Failed to get person by ID: %d", id)
 		return nil, fmt.Errorf("failed to get person: %w", err)
 	}
+	if err := r.loadNationalities(ctx, []*domain.Person{person}); err != nil {
+		logrus.WithError(err).Errorf("Failed to get nationalities of person %d", id)
+		return nil, fmt.Errorf("failed to get person: %w", err)
+	}
+	if err := r.loadProvenance(ctx, []*domain.Person{person}); err != nil {
+		logrus.WithError(err).Errorf("Failed to get provenance of person %d", id)
+		return nil, fmt.Errorf("failed to get person: %w", err)
+	}
 	logrus.Debugf("Retrieved person with ID %d: %+v", id, person)
 	return person, nil
 }
 
-func (r *PersonRepository) GetAll(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]*domain.Person, int, error) {
-	// Формируем запрос для получения записей
-	query := "SELECT id, name, surname, patronymic, age, gender, nationality, created_at FROM people"
-	var args []interface{}
-	var conditions []string
-	argIndex := 1
-
-	if name, ok := filters["name"]; ok {
-		conditions = append(conditions, fmt.Sprintf("name ILIKE $%d", argIndex))
-		args = append(args, "%"+name.(string)+"%")
-		argIndex++
-	}
-	if surname, ok := filters["surname"]; ok {
-		conditions = append(conditions, fmt.Sprintf("surname ILIKE $%d", argIndex))
-		args = append(args, "%"+surname.(string)+"%")
-		argIndex++
-	}
-	if age, ok := filters["age"]; ok {
-		conditions = append(conditions, fmt.Sprintf("age = $%d", argIndex))
-		args = append(args, age.(int))
-		argIndex++
-	}
-	if gender, ok := filters["gender"]; ok {
-		conditions = append(conditions, fmt.Sprintf("gender = $%d", argIndex))
-		args = append(args, gender.(string))
-		argIndex++
-	}
-	if nationality, ok := filters["nationality"]; ok {
-		conditions = append(conditions, fmt.Sprintf("nationality ILIKE $%d", argIndex))
-		args = append(args, "%"+nationality.(string)+"%")
-		argIndex++
-	}
-
-	// Сохраняем условия для COUNT-запроса
-	countQuery := "SELECT COUNT(*) FROM people"
-	countArgs := args
-	if len(conditions) > 0 {
-		query += " WHERE " + strings.Join(conditions, " AND ")
-		countQuery += " WHERE " + strings.Join(conditions, " AND ")
+// getByIDs returns the people with the given IDs by ID, with their nationalities and provenance.
+// Missing IDs are left out.
+func (r *PersonRepository) getByIDs(ctx context.Context, ids []int64) (map[int64]*domain.Person, error) {
+	query := `
+		SELECT id, name, surname, patronymic, name_normalized, surname_normalized, patronymic_normalized, country_id, age, age_localization, gender, gender_probability, gender_count, gender_localization, gender_source,
+		       nationality, nationality_probability, enrichment_status, created_at, deleted_at
+		FROM people WHERE id = ANY($1)
+	`
+	rows, err := r.db.Query(ctx, query, ids)
+	if err != nil {
+		r.log.WithError(err).Error("Failed to get people by ID")
+		return nil, fmt.Errorf("failed to get people: %w", err)
+	}
+	defer rows.Close()
+
+	people := make(map[int64]*domain.Person, len(ids))
+	list := make([]*domain.Person, 0, len(ids))
+	for rows.Next() {
+		person := &domain.Person{}
+		if err := rows.Scan(
+			&person.ID, &person.Name, &person.Surname, &person.Patronymic,
+			&person.NameNormalized, &person.SurnameNormalized, &person.PatronymicNormalized, &person.CountryID, &person.Age, &person.AgeLocalization, &person.Gender,
+			&person.GenderProbability, &person.GenderCount, &person.GenderLocalization, &person.GenderSource, &person.Nationality, &person.NationalityProbability,
+			&person.EnrichmentStatus, &person.CreatedAt, &person.DeletedAt,
+		); err != nil {
+			r.log.WithError(err).Error("Failed to scan person")
+			return nil, fmt.Errorf("failed to scan person: %w", err)
+		}
+		people[person.ID] = person
+		list = append(list, person)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	rows.Close()
 
-	// Добавляем LIMIT и OFFSET для основного запроса
-	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
-	args = append(args, limit, offset)
+	if err := r.loadNationalities(ctx, list); err != nil {
+		return nil, err
+	}
+	if err := r.loadProvenance(ctx, list); err != nil {
+		return nil, err
+	}
+	return people, nil
+}
 
-	// Выполняем COUNT-запрос
-	var total int
-	err := r.db.QueryRow(ctx, countQuery, countArgs...).Scan(&total)
+func (r *PersonRepository) GetAll(ctx context.Context, q domain.PersonQuery) (*domain.PersonPage, error) {
+	// Формируем запрос для получения записей
+	query := `
+		SELECT id, name, surname, patronymic, name_normalized, surname_normalized, patronymic_normalized, country_id, age, age_localization, gender, gender_probability, gender_count, gender_localization, gender_source,
+		       nationality, nationality_probability, enrichment_status, created_at, deleted_at
+		FROM people`
+	conditions, args := personConditions(q.Filter, 1)
+
+	// Считаем записи в запрошенном режиме
+	total, countMode, err := r.countPeople(ctx, q.Count, conditions, args)
 	if err != nil {
-		r.log.WithFields(logrus.Fields{
-			"query": countQuery,
-			"args":  countArgs,
-			"error": err,
-		}).Error("Failed to count people")
-		return nil, 0, fmt.Errorf("failed to count people: %w", err)
+		return nil, err
+	}
+
+	// Курсор заменяет OFFSET условием на ключи сортировки; страницу назад читаем в обратном порядке
+	b := &conditionBuilder{conditions: slices.Clone(conditions), args: slices.Clone(args), next: len(args) + 1}
+	backward := q.Cursor != nil && q.Cursor.Backward
+	offset := q.Offset
+	if q.Cursor != nil {
+		if err := b.keyset(q.Cursor); err != nil {
+			return nil, err
+		}
+		offset = 0
+	}
+	if len(b.conditions) > 0 {
+		query += " WHERE " + strings.Join(b.conditions, " AND ")
 	}
 
+	// Запрашиваем на одну запись больше, чтобы узнать, есть ли следующая страница
+	query += fmt.Sprintf(" ORDER BY %s LIMIT %s OFFSET %s", personOrderBy(q.Sort, backward), b.arg(q.Limit+1), b.arg(offset))
+	args = b.args
+
 	// Выполняем основной запрос
 	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
@@ -141,7 +386,7 @@ func (r *PersonRepository) GetAll(ctx context.Context, filters map[string]interf
 			"args":  args,
 			"error": err,
 		}).Error("Failed to get people")
-		return nil, 0, fmt.Errorf("failed to get people: %w", err)
+		return nil, fmt.Errorf("failed to get people: %w", err)
 	}
 	defer rows.Close()
 
@@ -154,13 +399,25 @@ func (r *PersonRepository) GetAll(ctx context.Context, filters map[string]interf
 			&person.Name,
 			&person.Surname,
 			&patronymic,
+			&person.NameNormalized,
+			&person.SurnameNormalized,
+			&person.PatronymicNormalized,
+			&person.CountryID,
 			&person.Age,
+			&person.AgeLocalization,
 			&person.Gender,
+			&person.GenderProbability,
+			&person.GenderCount,
+			&person.GenderLocalization,
+			&person.GenderSource,
 			&person.Nationality,
+			&person.NationalityProbability,
+			&person.EnrichmentStatus,
 			&person.CreatedAt,
+			&person.DeletedAt,
 		); err != nil {
 			r.log.WithError(err).Error("Failed to scan person")
-			return nil, 0, fmt.Errorf("failed to scan person: %w", err)
+			return nil, fmt.Errorf("failed to scan person: %w", err)
 		}
 		if patronymic.Valid {
 			person.Patronymic = &patronymic.String
@@ -170,38 +427,395 @@ func (r *PersonRepository) GetAll(ctx context.Context, filters map[string]interf
 
 	if err := rows.Err(); err != nil {
 		r.log.WithError(err).Error("Rows error")
-		return nil, 0, fmt.Errorf("rows error: %w", err)
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	rows.Close()
+
+	// Лишнюю запись отбрасываем; страница назад прочитана в обратном порядке
+	more := len(people) > q.Limit
+	if more {
+		people = people[:q.Limit]
+	}
+	if backward {
+		slices.Reverse(people)
+	}
+
+	if err := r.loadNationalities(ctx, people); err != nil {
+		r.log.WithError(err).Error("Failed to get nationalities")
+		return nil, err
+	}
+	if err := r.loadProvenance(ctx, people); err != nil {
+		r.log.WithError(err).Error("Failed to get provenance")
+		return nil, err
+	}
+
+	page := &domain.PersonPage{People: people, Total: total, CountMode: countMode}
+	if len(people) > 0 {
+		first, last := people[0], people[len(people)-1]
+		if (backward && more) || (!backward && (q.Cursor != nil || offset > 0)) {
+			page.Prev = domain.NewCursor(q.Sort, first, true)
+		}
+		if more || backward {
+			page.Next = domain.NewCursor(q.Sort, last, false)
+		}
 	}
 
 	r.log.WithField("count", len(people)).Debug("Retrieved people")
-	return people, total, nil
+	return page, nil
 }
 
+// Search finds people by trigram word similarity of the query or its normalized key to their names, or by
+// all words of the query matching whole words of the names. Both conditions are served by the GIN indexes
+// of migration 0014; the similarity threshold of the trigram operator is set for the search's transaction.
+func (r *PersonRepository) Search(ctx context.Context, search domain.PersonSearch) ([]*domain.PersonSearchResult, error) {
+	query := `
+		SELECT id, name, surname, patronymic, name_normalized, surname_normalized, patronymic_normalized, country_id, age, age_localization, gender, gender_probability, gender_count, gender_localization, gender_source,
+		       nationality, nationality_probability, enrichment_status, created_at, deleted_at,
+		       CASE WHEN search_vector @@ (plainto_tsquery('simple', $1) || plainto_tsquery('simple', $2)) THEN 1
+		            ELSE GREATEST(word_similarity($1, search_text), word_similarity($2, search_text)) END AS similarity
+		FROM people
+		WHERE ($1 <% search_text OR $2 <% search_text
+		       OR search_vector @@ plainto_tsquery('simple', $1) OR search_vector @@ plainto_tsquery('simple', $2))`
+	conditions, args := personConditions(search.Filter, 3)
+	args = append([]interface{}{strings.ToLower(search.Query), search.Key}, args...)
+	for _, condition := range conditions {
+		query += " AND " + condition
+	}
+	query += fmt.Sprintf(" ORDER BY similarity DESC, id LIMIT $%d", len(args)+1)
+	args = append(args, search.Limit)
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		r.log.WithError(err).Error("Failed to begin transaction")
+		return nil, fmt.Errorf("failed to search people: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	threshold := strconv.FormatFloat(search.MinSimilarity, 'f', -1, 64)
+	if _, err := tx.Exec(ctx, "SELECT set_config('pg_trgm.word_similarity_threshold', $1, true)", threshold); err != nil {
+		r.log.WithError(err).Error("Failed to set the similarity threshold")
+		return nil, fmt.Errorf("failed to search people: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"query": query,
+			"args":  args,
+			"error": err,
+		}).Error("Failed to search people")
+		return nil, fmt.Errorf("failed to search people: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*domain.PersonSearchResult
+	for rows.Next() {
+		result := &domain.PersonSearchResult{Person: &domain.Person{}}
+		person := result.Person
+		var patronymic sql.NullString
+		if err := rows.Scan(
+			&person.ID,
+			&person.Name,
+			&person.Surname,
+			&patronymic,
+			&person.NameNormalized,
+			&person.SurnameNormalized,
+			&person.PatronymicNormalized,
+			&person.CountryID,
+			&person.Age,
+			&person.AgeLocalization,
+			&person.Gender,
+			&person.GenderProbability,
+			&person.GenderCount,
+			&person.GenderLocalization,
+			&person.GenderSource,
+			&person.Nationality,
+			&person.NationalityProbability,
+			&person.EnrichmentStatus,
+			&person.CreatedAt,
+			&person.DeletedAt,
+			&result.Similarity,
+		); err != nil {
+			r.log.WithError(err).Error("Failed to scan person")
+			return nil, fmt.Errorf("failed to scan person: %w", err)
+		}
+		if patronymic.Valid {
+			person.Patronymic = &patronymic.String
+		}
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		r.log.WithError(err).Error("Rows error")
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	rows.Close()
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to search people: %w", err)
+	}
+
+	people := make([]*domain.Person, len(results))
+	for i, result := range results {
+		people[i] = result.Person
+	}
+	if err := r.loadNationalities(ctx, people); err != nil {
+		r.log.WithError(err).Error("Failed to get nationalities")
+		return nil, err
+	}
+	if err := r.loadProvenance(ctx, people); err != nil {
+		r.log.WithError(err).Error("Failed to get provenance")
+		return nil, err
+	}
+
+	r.log.WithFields(logrus.Fields{
+		"query": search.Query,
+		"count": len(results),
+	}).Debug("Searched people")
+	return results, nil
+}
+
+// estimatedCountExactBelow is the row estimate under which an estimated count is replaced by an exact one,
+// since counting that few rows is cheap and small estimates are the most visibly wrong.
+const estimatedCountExactBelow = 1000
+
+// countPeople counts the people matching conditions in the given mode and returns the mode actually used.
+// Estimates come from the planner's row estimate of the filtered scan, see EXPLAIN.
+func (r *PersonRepository) countPeople(ctx context.Context, mode string, conditions []string, args []interface{}) (*int, string, error) {
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	switch mode {
+	case domain.CountNone:
+		return nil, domain.CountNone, nil
+	case domain.CountEstimated:
+		query := "EXPLAIN (FORMAT JSON) SELECT 1 FROM people" + where
+		var plan []struct {
+			Plan struct {
+				Rows float64 `json:"Plan Rows"`
+			} `json:"Plan"`
+		}
+		var data []byte
+		if err := r.db.QueryRow(ctx, query, args...).Scan(&data); err != nil {
+			r.log.WithFields(logrus.Fields{
+				"query": query,
+				"args":  args,
+				"error": err,
+			}).Error("Failed to estimate people")
+			return nil, "", fmt.Errorf("failed to estimate people: %w", err)
+		}
+		if err := json.Unmarshal(data, &plan); err != nil || len(plan) == 0 {
+			r.log.WithField("plan", string(data)).Error("Failed to read the query plan")
+			return nil, "", fmt.Errorf("failed to estimate people: unexpected plan %s", data)
+		}
+		if estimate := int(plan[0].Plan.Rows); estimate >= estimatedCountExactBelow {
+			return &estimate, domain.CountEstimated, nil
+		}
+	}
+
+	query := "SELECT COUNT(*) FROM people" + where
+	var total int
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&total); err != nil {
+		r.log.WithFields(logrus.Fields{
+			"query": query,
+			"args":  args,
+			"error": err,
+		}).Error("Failed to count people")
+		return nil, "", fmt.Errorf("failed to count people: %w", err)
+	}
+	return &total, domain.CountExact, nil
+}
+
+// Update replaces the person's fields. Provider confidence and localization are kept only for values that did not change;
+// changed enrichable fields are recorded as set manually.
 func (r *PersonRepository) Update(ctx context.Context, id int64, person *domain.Person) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logrus.Errorf("Failed to begin transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
-        UPDATE people
-        SET name = $1, surname = $2, patronymic = $3, age = $4, gender = $5, nationality = $6
-        WHERE id = $7
+        UPDATE people p
+        SET name = $1, surname = $2, patronymic = $3, age = $4, gender = $5, nationality = $6,
+            name_normalized = $8, surname_normalized = $9, patronymic_normalized = $10,
+            age_localization = CASE WHEN old.age IS NOT DISTINCT FROM $4 THEN p.age_localization END,
+            gender_probability = CASE WHEN old.gender IS NOT DISTINCT FROM $5 THEN p.gender_probability END,
+            gender_count = CASE WHEN old.gender IS NOT DISTINCT FROM $5 THEN p.gender_count END,
+            gender_localization = CASE WHEN old.gender IS NOT DISTINCT FROM $5 THEN p.gender_localization END,
+            gender_source = CASE WHEN old.gender IS NOT DISTINCT FROM $5 THEN p.gender_source END,
+            nationality_probability = CASE WHEN old.nationality IS NOT DISTINCT FROM $6 THEN p.nationality_probability END
+        FROM (SELECT id, age, gender, nationality FROM people WHERE id = $7 AND deleted_at IS NULL FOR UPDATE) old
+        WHERE p.id = old.id
+        RETURNING old.age IS DISTINCT FROM p.age, old.gender IS DISTINCT FROM p.gender, old.nationality IS DISTINCT FROM p.nationality
     `
-	result, err := r.db.Exec(ctx, query,
+	var ageChanged, genderChanged, nationalityChanged bool
+	err = tx.QueryRow(ctx, query,
 		person.Name, person.Surname, person.Patronymic, person.Age, person.Gender, person.Nationality, id,
-	)
-
+		person.NameNormalized, person.SurnameNormalized, person.PatronymicNormalized,
+	).Scan(&ageChanged, &genderChanged, &nationalityChanged)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			logrus.Warnf("No person updated with ID %d", id)
+			return ErrNotFound
+		}
 		logrus.Errorf("Failed to update person ID %d: %v", id, err)
 		return err
 	}
 
+	if nationalityChanged {
+		if _, err := tx.Exec(ctx, "DELETE FROM person_nationalities WHERE person_id = $1", id); err != nil {
+			logrus.Errorf("Failed to clear nationalities of person ID %d: %v", id, err)
+			return err
+		}
+	}
+
+	// Values changed by hand are locked against automatic re-enrichment; cleared values are unlocked
+	changes := []struct {
+		field   string
+		changed bool
+		set     bool
+	}{
+		{domain.FieldAge, ageChanged, person.Age != 0},
+		{domain.FieldGender, genderChanged, person.Gender != ""},
+		{domain.FieldNationality, nationalityChanged, person.Nationality != ""},
+	}
+	now := time.Now().UTC()
+	for _, change := range changes {
+		if !change.changed {
+			continue
+		}
+		if change.set {
+			err = upsertProvenance(ctx, tx, id, change.field, &domain.FieldProvenance{Source: domain.ProvenanceManual, FetchedAt: now})
+		} else {
+			_, err = tx.Exec(ctx, "DELETE FROM person_field_provenance WHERE person_id = $1 AND field = $2", id, change.field)
+		}
+		if err != nil {
+			logrus.Errorf("Failed to store provenance of %s of person ID %d: %v", change.field, id, err)
+			return err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		logrus.Errorf("Failed to commit update of person ID %d: %v", id, err)
+		return err
+	}
+	logrus.Debugf("Updated person with ID: %d", id)
+	return nil
+}
+
+func (r *PersonRepository) FillEnrichment(ctx context.Context, id int64, patch *domain.Person, fields []string, overwrite, force bool) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logrus.Errorf("Failed to begin transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the person first, so a concurrent manual update cannot slip in between reading the locks and writing
+	var manual []string
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE(array_agg(pr.field) FILTER (WHERE pr.source = 'manual'), '{}')
+		FROM (SELECT id FROM people WHERE id = $1 AND deleted_at IS NULL FOR UPDATE) p
+		LEFT JOIN person_field_provenance pr ON pr.person_id = p.id
+		GROUP BY p.id
+	`, id).Scan(&manual)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		logrus.Errorf("Failed to lock person ID %d for enrichment: %v", id, err)
+		return err
+	}
+	if !force {
+		fields = slices.DeleteFunc(slices.Clone(fields), func(field string) bool {
+			return slices.Contains(manual, field)
+		})
+	}
+
+	setAge := slices.Contains(fields, domain.FieldAge) && patch.Age != 0
+	setGender := slices.Contains(fields, domain.FieldGender) && patch.Gender != ""
+	setNationality := slices.Contains(fields, domain.FieldNationality) && patch.Nationality != ""
+
+	query := `
+        UPDATE people p
+        SET age = CASE WHEN $8 AND ($11 OR COALESCE(old.age, 0) = 0) THEN $2 ELSE p.age END,
+            age_localization = CASE WHEN $8 AND ($11 OR COALESCE(old.age, 0) = 0) THEN $12 ELSE p.age_localization END,
+            gender = CASE WHEN $9 AND ($11 OR COALESCE(old.gender, '') = '') THEN $3 ELSE p.gender END,
+            gender_probability = CASE WHEN $9 AND ($11 OR COALESCE(old.gender, '') = '') THEN $4 ELSE p.gender_probability END,
+            gender_count = CASE WHEN $9 AND ($11 OR COALESCE(old.gender, '') = '') THEN $5 ELSE p.gender_count END,
+            gender_localization = CASE WHEN $9 AND ($11 OR COALESCE(old.gender, '') = '') THEN $13 ELSE p.gender_localization END,
+            gender_source = CASE WHEN $9 AND ($11 OR COALESCE(old.gender, '') = '') THEN $14 ELSE p.gender_source END,
+            nationality = CASE WHEN $10 AND ($11 OR COALESCE(old.nationality, '') = '') THEN $6 ELSE p.nationality END,
+            nationality_probability = CASE WHEN $10 AND ($11 OR COALESCE(old.nationality, '') = '') THEN $7 ELSE p.nationality_probability END
+        FROM (SELECT id, age, gender, nationality FROM people WHERE id = $1 AND deleted_at IS NULL FOR UPDATE) old
+        WHERE p.id = old.id
+        RETURNING $8 AND ($11 OR COALESCE(old.age, 0) = 0),
+                  $9 AND ($11 OR COALESCE(old.gender, '') = ''),
+                  $10 AND ($11 OR COALESCE(old.nationality, '') = '')
+    `
+	var ageFilled, genderFilled, nationalityFilled bool
+	err = tx.QueryRow(ctx, query,
+		id, patch.Age, patch.Gender, patch.GenderProbability, patch.GenderCount, patch.Nationality, patch.NationalityProbability,
+		setAge, setGender, setNationality, overwrite, patch.AgeLocalization, patch.GenderLocalization, patch.GenderSource,
+	).Scan(&ageFilled, &genderFilled, &nationalityFilled)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		logrus.Errorf("Failed to fill enrichment of person ID %d: %v", id, err)
+		return err
+	}
+
+	if nationalityFilled {
+		if _, err := tx.Exec(ctx, "DELETE FROM person_nationalities WHERE person_id = $1", id); err != nil {
+			logrus.Errorf("Failed to clear nationalities of person ID %d: %v", id, err)
+			return err
+		}
+		if err := insertNationalities(ctx, tx, id, patch.Nationalities); err != nil {
+			logrus.Errorf("Failed to store nationalities of person %d: %v", id, err)
+			return err
+		}
+	}
+
+	filled := map[string]bool{
+		domain.FieldAge:         ageFilled,
+		domain.FieldGender:      genderFilled,
+		domain.FieldNationality: nationalityFilled,
+	}
+	for _, field := range domain.EnrichableFields {
+		provenance, ok := patch.Provenance[field]
+		if !filled[field] || !ok {
+			continue
+		}
+		if err := upsertProvenance(ctx, tx, id, field, provenance); err != nil {
+			logrus.Errorf("Failed to store provenance of %s of person ID %d: %v", field, id, err)
+			return err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		logrus.Errorf("Failed to commit enrichment of person ID %d: %v", id, err)
+		return err
+	}
+	logrus.Debugf("Filled enrichment of person with ID: %d", id)
+	return nil
+}
+
+func (r *PersonRepository) SetEnrichmentStatus(ctx context.Context, id int64, status string) error {
+	result, err := r.db.Exec(ctx, "UPDATE people SET enrichment_status = $1 WHERE id = $2 AND deleted_at IS NULL", status, id)
+	if err != nil {
+		logrus.Errorf("Failed to set enrichment status of person ID %d: %v", id, err)
+		return err
+	}
 	if result.RowsAffected() == 0 {
-		logrus.Warnf("No person updated with ID %d", id)
 		return ErrNotFound
 	}
-	logrus.Debugf("Updated person with ID: %d", id)
+	logrus.Debugf("Set enrichment status of person %d to %s", id, status)
 	return nil
 }
 
 func (r *PersonRepository) Delete(ctx context.Context, id int64) error {
-	query := "DELETE FROM people WHERE id = $1"
+	query := "UPDATE people SET deleted_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at IS NULL"
 	result, err := r.db.Exec(ctx, query, id)
 
 	if err != nil {
@@ -215,3 +829,51 @@ func (r *PersonRepository) Delete(ctx context.Context, id int64) error {
 	logrus.Debugf("Deleted person with ID: %d", id)
 	return nil
 }
+
+func (r *PersonRepository) Restore(ctx context.Context, id int64) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		logrus.Errorf("Failed to begin transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, "UPDATE people SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL", id)
+	if err != nil {
+		logrus.Errorf("Failed to restore person ID %d: %v", id, err)
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		var exists bool
+		if err := tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM people WHERE id = $1)", id).Scan(&exists); err != nil {
+			logrus.Errorf("Failed to check person ID %d: %v", id, err)
+			return err
+		}
+		if exists {
+			return ErrNotDeleted
+		}
+		logrus.Warnf("No person restored with ID %d", id)
+		return ErrNotFound
+	}
+	// Undo the merge of a restored duplicate; its survivor keeps the fields taken over
+	query := "UPDATE person_merges SET undone_at = CURRENT_TIMESTAMP WHERE merged_id = $1 AND undone_at IS NULL"
+	if _, err := tx.Exec(ctx, query, id); err != nil {
+		logrus.Errorf("Failed to undo merge of person ID %d: %v", id, err)
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		logrus.Errorf("Failed to commit restore of person ID %d: %v", id, err)
+		return err
+	}
+	logrus.Debugf("Restored person with ID: %d", id)
+	return nil
+}
+
+func (r *PersonRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	result, err := r.db.Exec(ctx, "DELETE FROM people WHERE deleted_at < $1", deletedBefore)
+	if err != nil {
+		r.log.WithError(err).Error("Failed to purge deleted people")
+		return 0, fmt.Errorf("failed to purge people: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
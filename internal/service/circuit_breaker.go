@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"person-service/internal/config"
+	"person-service/internal/domain"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("enrichment provider circuit is open")
+
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half-open"
+)
+
+// CircuitBreaker stops calling a provider after FailureThreshold consecutive failures.
+// After OpenTimeout it lets HalfOpenRequests probes through; one success closes it again.
+type CircuitBreaker struct {
+	cfg config.BreakerConfig
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	probes   int
+	openedAt time.Time
+}
+
+func NewCircuitBreaker(cfg config.BreakerConfig) *CircuitBreaker {
+	if cfg.FailureThreshold < 1 {
+		cfg.FailureThreshold = 1
+	}
+	if cfg.HalfOpenRequests < 1 {
+		cfg.HalfOpenRequests = 1
+	}
+	return &CircuitBreaker{cfg: cfg, state: CircuitClosed}
+}
+
+// allow reports whether a call may go through and reserves a probe slot when half-open
+func (b *CircuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitOpen && time.Since(b.openedAt) >= b.cfg.OpenTimeout {
+		b.state = CircuitHalfOpen
+		b.probes = 0
+	}
+	switch b.state {
+	case CircuitOpen:
+		return false
+	case CircuitHalfOpen:
+		if b.probes >= b.cfg.HalfOpenRequests {
+			return false
+		}
+		b.probes++
+	}
+	return true
+}
+
+func (b *CircuitBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err == nil {
+		b.state = CircuitClosed
+		b.failures = 0
+		b.probes = 0
+		return
+	}
+
+	b.failures++
+	if b.state == CircuitHalfOpen || b.failures >= b.cfg.FailureThreshold {
+		b.state = CircuitOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// release gives back a probe slot without judging the provider
+func (b *CircuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitHalfOpen && b.probes > 0 {
+		b.probes--
+	}
+}
+
+func (b *CircuitBreaker) status(provider string) domain.EnrichmentProviderStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := domain.EnrichmentProviderStatus{
+		Provider:            provider,
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+	}
+	if b.state != CircuitClosed {
+		openedAt := b.openedAt.UTC()
+		status.OpenedAt = &openedAt
+	}
+	return status
+}
+
+// CircuitBreakers holds one breaker per enrichment provider
+type CircuitBreakers struct {
+	cfg config.BreakerConfig
+
+	mu       sync.Mutex
+	breakers map[string]*CircuitBreaker
+	order    []string
+}
+
+func NewCircuitBreakers(cfg config.BreakerConfig) *CircuitBreakers {
+	return &CircuitBreakers{
+		cfg:      cfg,
+		breakers: make(map[string]*CircuitBreaker),
+	}
+}
+
+func (c *CircuitBreakers) breaker(provider string) *CircuitBreaker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b, ok := c.breakers[provider]
+	if !ok {
+		b = NewCircuitBreaker(c.cfg)
+		c.breakers[provider] = b
+		c.order = append(c.order, provider)
+	}
+	return b
+}
+
+// Wrap returns an Enricher that fails fast with ErrCircuitOpen while e's circuit is open
+func (c *CircuitBreakers) Wrap(e Enricher) Enricher {
+	return &breakerEnricher{Enricher: e, breaker: c.breaker(e.Name())}
+}
+
+// Status returns the breaker state of every provider in registration order
+func (c *CircuitBreakers) Status() []domain.EnrichmentProviderStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	statuses := make([]domain.EnrichmentProviderStatus, 0, len(c.order))
+	for _, provider := range c.order {
+		statuses = append(statuses, c.breakers[provider].status(provider))
+	}
+	return statuses
+}
+
+type breakerEnricher struct {
+	Enricher
+	breaker *CircuitBreaker
+}
+
+func (e *breakerEnricher) Enrich(ctx context.Context, query EnrichmentQuery) (*EnrichmentResult, error) {
+	if !e.breaker.allow() {
+		return nil, fmt.Errorf("%s: %w", e.Name(), ErrCircuitOpen)
+	}
+	result, err := e.Enricher.Enrich(ctx, query)
+	if err != nil && ctx.Err() != nil {
+		// The caller gave up, which says nothing about the provider's health
+		e.breaker.release()
+		return nil, err
+	}
+	e.breaker.record(err)
+	return result, err
+}
+
+// EnrichBatch lets the whole batch through one breaker check. The batch only counts as a failure
+// when no query got an answer, so a single unknown name does not trip the breaker.
+func (e *breakerEnricher) EnrichBatch(ctx context.Context, queries []EnrichmentQuery) ([]*EnrichmentResult, []error) {
+	if !e.breaker.allow() {
+		errs := make([]error, len(queries))
+		for i := range errs {
+			errs[i] = fmt.Errorf("%s: %w", e.Name(), ErrCircuitOpen)
+		}
+		return make([]*EnrichmentResult, len(queries)), errs
+	}
+	results, errs := enrichBatch(ctx, e.Enricher, queries)
+
+	var err error
+	for i := range errs {
+		if errs[i] == nil {
+			err = nil
+			break
+		}
+		err = errs[i]
+	}
+	if err != nil && ctx.Err() != nil {
+		e.breaker.release()
+		return results, errs
+	}
+	e.breaker.record(err)
+	return results, errs
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"person-service/internal/config"
+	"testing"
+	"time"
+)
+
+var errProvider = errors.New("provider failed")
+
+// stubEnricher fails for the names in fail and answers the rest
+type stubEnricher struct {
+	fail map[string]bool
+}
+
+func (e *stubEnricher) Name() string {
+	return "stub"
+}
+
+func (e *stubEnricher) Fields() []string {
+	return nil
+}
+
+func (e *stubEnricher) Enrich(ctx context.Context, query EnrichmentQuery) (*EnrichmentResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if e.fail[query.Name] {
+		return nil, errProvider
+	}
+	return &EnrichmentResult{Age: 30}, nil
+}
+
+func assertState(t *testing.T, b *CircuitBreaker, state string) {
+	t.Helper()
+	if got := b.status("stub").State; got != state {
+		t.Fatalf("state = %s, want %s", got, state)
+	}
+}
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	b := NewCircuitBreaker(config.BreakerConfig{FailureThreshold: 2, OpenTimeout: 20 * time.Millisecond, HalfOpenRequests: 1})
+
+	b.record(errProvider)
+	assertState(t, b, CircuitClosed)
+	b.record(nil)
+	b.record(errProvider)
+	assertState(t, b, CircuitClosed) // a success resets the count
+	b.record(errProvider)
+	assertState(t, b, CircuitOpen)
+	if b.allow() {
+		t.Fatal("open circuit let a call through")
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	if !b.allow() {
+		t.Fatal("no probe let through after the open timeout")
+	}
+	assertState(t, b, CircuitHalfOpen)
+	if b.allow() {
+		t.Fatal("half-open circuit let more probes through than configured")
+	}
+
+	// A failed probe opens the circuit at once
+	b.record(errProvider)
+	assertState(t, b, CircuitOpen)
+
+	time.Sleep(25 * time.Millisecond)
+	if !b.allow() {
+		t.Fatal("no probe let through after the open timeout")
+	}
+	b.record(nil)
+	assertState(t, b, CircuitClosed)
+	if status := b.status("stub"); status.ConsecutiveFailures != 0 || status.OpenedAt != nil {
+		t.Errorf("closed circuit status = %+v", status)
+	}
+	if !b.allow() || !b.allow() {
+		t.Error("closed circuit rejected a call")
+	}
+}
+
+func TestCircuitBreakerReleasesProbeOnCancel(t *testing.T) {
+	breakers := NewCircuitBreakers(config.BreakerConfig{FailureThreshold: 1, OpenTimeout: 10 * time.Millisecond, HalfOpenRequests: 1})
+	enricher := breakers.Wrap(&stubEnricher{fail: map[string]bool{"bad": true}})
+	b := breakers.breaker("stub")
+
+	if _, err := enricher.Enrich(context.Background(), EnrichmentQuery{Name: "bad"}); !errors.Is(err, errProvider) {
+		t.Fatalf("error = %v, want %v", err, errProvider)
+	}
+	assertState(t, b, CircuitOpen)
+	if _, err := enricher.Enrich(context.Background(), EnrichmentQuery{Name: "good"}); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("error = %v, want %v", err, ErrCircuitOpen)
+	}
+	time.Sleep(15 * time.Millisecond)
+
+	// The probe's caller gives up: the slot is given back and the circuit stays half-open
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := enricher.Enrich(ctx, EnrichmentQuery{Name: "good"}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("error = %v, want %v", err, context.Canceled)
+	}
+	assertState(t, b, CircuitHalfOpen)
+
+	if _, err := enricher.Enrich(context.Background(), EnrichmentQuery{Name: "good"}); err != nil {
+		t.Fatalf("the released probe slot was not reused: %v", err)
+	}
+	assertState(t, b, CircuitClosed)
+}
+
+func TestCircuitBreakerIgnoresCancelledCalls(t *testing.T) {
+	breakers := NewCircuitBreakers(config.BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Minute, HalfOpenRequests: 1})
+	enricher := breakers.Wrap(&stubEnricher{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := enricher.Enrich(ctx, EnrichmentQuery{Name: "good"}); err == nil {
+		t.Fatal("expected the cancelled call to fail")
+	}
+	assertState(t, breakers.breaker("stub"), CircuitClosed)
+}
+
+func TestCircuitBreakerBatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		names []string
+		state string
+	}{
+		{name: "all answered", names: []string{"ann", "ivan"}, state: CircuitClosed},
+		{name: "some failed", names: []string{"ann", "bad", "worse"}, state: CircuitClosed},
+		{name: "all failed", names: []string{"bad", "worse"}, state: CircuitOpen},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breakers := NewCircuitBreakers(config.BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Minute, HalfOpenRequests: 1})
+			enricher := breakers.Wrap(&stubEnricher{fail: map[string]bool{"bad": true, "worse": true}}).(BatchEnricher)
+
+			queries := make([]EnrichmentQuery, len(tt.names))
+			for i, name := range tt.names {
+				queries[i] = EnrichmentQuery{Name: name}
+			}
+			results, errs := enricher.EnrichBatch(context.Background(), queries)
+			if len(results) != len(queries) || len(errs) != len(queries) {
+				t.Fatalf("got %d results and %d errors for %d queries", len(results), len(errs), len(queries))
+			}
+			assertState(t, breakers.breaker("stub"), tt.state)
+
+			if tt.state != CircuitOpen {
+				return
+			}
+			_, errs = enricher.EnrichBatch(context.Background(), queries)
+			for i, err := range errs {
+				if !errors.Is(err, ErrCircuitOpen) {
+					t.Errorf("query %d: error = %v, want %v", i, err, ErrCircuitOpen)
+				}
+			}
+		})
+	}
+}
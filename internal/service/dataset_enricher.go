@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io"
+	"os"
+	"path/filepath"
+	"person-service/internal/domain"
+	"person-service/internal/names"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const ProviderDataset = "dataset"
+
+// DatasetEntry holds the statistics of one name. Zero values mean the dataset has no data for the field.
+type DatasetEntry struct {
+	Name              string                      `json:"name"`
+	Age               int                         `json:"age,omitempty"`
+	Gender            string                      `json:"gender,omitempty"`
+	GenderProbability float64                     `json:"gender_probability,omitempty"`
+	GenderCount       int                         `json:"gender_count,omitempty"`
+	Nationalities     []domain.CountryProbability `json:"nationalities,omitempty"`
+}
+
+// DatasetEnricher answers from a local file of name statistics, so enrichment works without network access.
+// The file is either CSV with the header
+//
+//	name,age,gender,gender_probability,gender_count,nationalities
+//
+// where nationalities reads like "RU:0.42;UA:0.17", or a JSON array of DatasetEntry.
+// Names are keyed the same way as person names, so Cyrillic entries match transliterated queries.
+type DatasetEnricher struct {
+	path    string
+	names   *names.Normalizer
+	entries atomic.Pointer[map[string]*DatasetEntry]
+	modTime time.Time
+	log     *logrus.Logger
+}
+
+// NewDatasetEnricher loads the dataset at path. Call Watch to pick up later changes of the file.
+func NewDatasetEnricher(path string, normalizer *names.Normalizer, log *logrus.Logger) (*DatasetEnricher, error) {
+	e := &DatasetEnricher{
+		path:  path,
+		names: normalizer,
+		log:   log,
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open enrichment dataset: %w", err)
+	}
+	if err := e.load(); err != nil {
+		return nil, err
+	}
+	e.modTime = info.ModTime()
+	return e, nil
+}
+
+func (e *DatasetEnricher) Name() string {
+	return ProviderDataset
+}
+
+func (e *DatasetEnricher) Fields() []string {
+	return domain.EnrichableFields
+}
+
+// Len returns the number of names in the loaded dataset
+func (e *DatasetEnricher) Len() int {
+	return len(*e.entries.Load())
+}
+
+// Enrich returns the statistics of the query's name. Unknown names give an empty result, not an error,
+// just like a remote provider that has no data for a name.
+func (e *DatasetEnricher) Enrich(ctx context.Context, query EnrichmentQuery) (*EnrichmentResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	entry, ok := (*e.entries.Load())[e.names.Key(query.Name)]
+	if !ok {
+		return &EnrichmentResult{}, nil
+	}
+
+	result := &EnrichmentResult{Age: entry.Age}
+	if entry.Gender != "" {
+		probability, count := entry.GenderProbability, entry.GenderCount
+		result.Gender = entry.Gender
+		result.GenderProbability = &probability
+		result.GenderCount = &count
+	}
+	if len(entry.Nationalities) > 0 {
+		result.Nationality = entry.Nationalities[0].CountryID
+		result.Nationalities = entry.Nationalities
+	}
+	return result, nil
+}
+
+// EnrichBatch looks the names up one by one; there is nothing to save by grouping local lookups
+func (e *DatasetEnricher) EnrichBatch(ctx context.Context, queries []EnrichmentQuery) ([]*EnrichmentResult, []error) {
+	results := make([]*EnrichmentResult, len(queries))
+	errs := make([]error, len(queries))
+	for i, query := range queries {
+		results[i], errs[i] = e.Enrich(ctx, query)
+	}
+	return results, errs
+}
+
+// Watch reloads the dataset whenever the file's modification time changes, until ctx is cancelled.
+// A file that fails to load is reported, the previous data is kept and the load is retried.
+func (e *DatasetEnricher) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(e.path)
+		if err != nil {
+			e.log.WithError(err).Warn("Failed to check enrichment dataset")
+			continue
+		}
+		if info.ModTime().Equal(e.modTime) {
+			continue
+		}
+		// The modification time is only taken on success, so a file caught half-written is read again
+		if err := e.load(); err != nil {
+			e.log.WithError(err).Error("Failed to reload enrichment dataset, keeping the previous one")
+			continue
+		}
+		e.modTime = info.ModTime()
+	}
+}
+
+func (e *DatasetEnricher) load() error {
+	file, err := os.Open(e.path)
+	if err != nil {
+		return fmt.Errorf("failed to open enrichment dataset: %w", err)
+	}
+	defer file.Close()
+
+	var entries []*DatasetEntry
+	switch strings.ToLower(filepath.Ext(e.path)) {
+	case ".json":
+		err = json.NewDecoder(file).Decode(&entries)
+	case ".csv":
+		entries, err = readDatasetCSV(file)
+	default:
+		return fmt.Errorf("unsupported enrichment dataset format: %s", e.path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse enrichment dataset %s: %w", e.path, err)
+	}
+
+	byName := make(map[string]*DatasetEntry, len(entries))
+	for _, entry := range entries {
+		key := e.names.Key(entry.Name)
+		if key == "" {
+			continue
+		}
+		sort.SliceStable(entry.Nationalities, func(i, j int) bool {
+			return entry.Nationalities[i].Probability > entry.Nationalities[j].Probability
+		})
+		byName[key] = entry
+	}
+	e.entries.Store(&byName)
+	e.log.WithFields(logrus.Fields{
+		"path":    e.path,
+		"entries": len(byName),
+	}).Info("Loaded enrichment dataset")
+	return nil
+}
+
+func readDatasetCSV(r io.Reader) ([]*DatasetEntry, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, errors.New("missing name column")
+	}
+	field := func(record []string, column string) string {
+		if i, ok := columns[column]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var entries []*DatasetEntry
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		entry := &DatasetEntry{
+			Name:   field(record, "name"),
+			Gender: strings.ToLower(field(record, "gender")),
+		}
+		var parseErr error
+		parseInt := func(column string) int {
+			value := field(record, column)
+			if value == "" || parseErr != nil {
+				return 0
+			}
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				parseErr = fmt.Errorf("line %d: invalid %s: %w", line, column, err)
+			}
+			return n
+		}
+		entry.Age = parseInt("age")
+		entry.GenderCount = parseInt("gender_count")
+		if value := field(record, "gender_probability"); value != "" {
+			if entry.GenderProbability, err = strconv.ParseFloat(value, 64); err != nil {
+				return nil, fmt.Errorf("line %d: invalid gender_probability: %w", line, err)
+			}
+		}
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		if entry.Nationalities, err = parseNationalities(field(record, "nationalities")); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+}
+
+// parseNationalities reads "RU:0.42;UA:0.17"
+func parseNationalities(value string) ([]domain.CountryProbability, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var countries []domain.CountryProbability
+	for _, item := range strings.Split(value, ";") {
+		country, probability, ok := strings.Cut(strings.TrimSpace(item), ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid nationality %q, expected COUNTRY:PROBABILITY", item)
+		}
+		p, err := strconv.ParseFloat(strings.TrimSpace(probability), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid nationality probability %q: %w", item, err)
+		}
+		countries = append(countries, domain.CountryProbability{
+			CountryID:   strings.ToUpper(strings.TrimSpace(country)),
+			Probability: p,
+		})
+	}
+	return countries, nil
+}
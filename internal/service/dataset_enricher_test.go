@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"person-service/internal/domain"
+	"person-service/internal/names"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadDatasetCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		want    []*DatasetEntry
+		wantErr string
+	}{
+		{
+			name: "full",
+			csv: "name,age,gender,gender_probability,gender_count,nationalities\n" +
+				"Ivan, 47, Male, 0.99, 1200, RU:0.42;UA:0.17\n",
+			want: []*DatasetEntry{{
+				Name:              "Ivan",
+				Age:               47,
+				Gender:            "male",
+				GenderProbability: 0.99,
+				GenderCount:       1200,
+				Nationalities: []domain.CountryProbability{
+					{CountryID: "RU", Probability: 0.42},
+					{CountryID: "UA", Probability: 0.17},
+				},
+			}},
+		},
+		{
+			name: "optional columns missing or empty",
+			csv:  "Gender,NAME\nfemale,Anna\n,Oleg\n",
+			want: []*DatasetEntry{{Name: "Anna", Gender: "female"}, {Name: "Oleg"}},
+		},
+		{
+			name: "header only",
+			csv:  "name,age\n",
+		},
+		{name: "empty file", csv: "", wantErr: "failed to read header"},
+		{name: "no name column", csv: "age,gender\n30,male\n", wantErr: "missing name column"},
+		{name: "bad age", csv: "name,age\nIvan,47\nAnna,old\n", wantErr: "line 3: invalid age"},
+		{name: "bad gender count", csv: "name,gender_count\nIvan,many\n", wantErr: "line 2: invalid gender_count"},
+		{name: "bad gender probability", csv: "name,gender_probability\nIvan,high\n", wantErr: "line 2: invalid gender_probability"},
+		{name: "bad nationalities", csv: "name,nationalities\nIvan,RU\n", wantErr: "line 2: invalid nationality"},
+		{name: "short line", csv: "name,age\nIvan\n", wantErr: "wrong number of fields"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readDatasetCSV(strings.NewReader(tt.csv))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNationalities(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []domain.CountryProbability
+		wantErr bool
+	}{
+		{value: ""},
+		{value: "ru:0.5", want: []domain.CountryProbability{{CountryID: "RU", Probability: 0.5}}},
+		{
+			value: " UA : 0.2 ; KZ:0.1",
+			want:  []domain.CountryProbability{{CountryID: "UA", Probability: 0.2}, {CountryID: "KZ", Probability: 0.1}},
+		},
+		{value: "RU", wantErr: true},
+		{value: "RU:high", wantErr: true},
+		{value: "RU:0.5;", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseNationalities(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func writeDataset(t *testing.T, path, content string, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDatasetEnricherJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "names.json")
+	writeDataset(t, path, `[
+		{"name": "Дмитрий", "age": 43, "gender": "male", "gender_probability": 0.99, "gender_count": 10,
+		 "nationalities": [{"country_id": "UA", "probability": 0.1}, {"country_id": "RU", "probability": 0.6}]},
+		{"name": " ", "age": 20}
+	]`, time.Now())
+	normalizer, _ := names.NewNormalizer(names.SchemeICAO)
+
+	e, err := NewDatasetEnricher(path, normalizer, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e.Len() != 1 {
+		t.Errorf("Len = %d, want 1, blank names are skipped", e.Len())
+	}
+
+	// The Cyrillic entry answers the transliterated query
+	result, err := e.Enrich(context.Background(), EnrichmentQuery{Name: "Dmitrii"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Age != 43 || result.Gender != "male" || *result.GenderProbability != 0.99 || *result.GenderCount != 10 {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if result.Nationality != "RU" || result.Nationalities[1].CountryID != "UA" {
+		t.Errorf("nationalities not ranked: %+v", result.Nationalities)
+	}
+
+	result, err = e.Enrich(context.Background(), EnrichmentQuery{Name: "Unknown"})
+	if err != nil || !reflect.DeepEqual(result, &EnrichmentResult{}) {
+		t.Errorf("unknown name gave %+v, %v, want an empty result", result, err)
+	}
+}
+
+// downEnricher is a provider of fields that always fails
+type downEnricher struct {
+	name   string
+	fields []string
+}
+
+func (e *downEnricher) Name() string {
+	return e.name
+}
+
+func (e *downEnricher) Fields() []string {
+	return e.fields
+}
+
+func (e *downEnricher) Enrich(ctx context.Context, query EnrichmentQuery) (*EnrichmentResult, error) {
+	return nil, errProvider
+}
+
+func TestWithFallbackProvenance(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "names.json")
+	writeDataset(t, path, `[{"name": "Ivan", "age": 43, "gender": "male", "nationalities": [{"country_id": "RU", "probability": 0.6}]}]`, time.Now())
+	normalizer, _ := names.NewNormalizer(names.SchemeICAO)
+	dataset, err := NewDatasetEnricher(path, normalizer, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		provider string
+		field    string
+	}{
+		{provider: ProviderAgify, field: domain.FieldAge},
+		{provider: ProviderGenderize, field: domain.FieldGender},
+		{provider: ProviderNationalize, field: domain.FieldNationality},
+	}
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			primary := &downEnricher{name: tt.provider, fields: []string{tt.field}}
+			result, err := WithFallback(primary, dataset).Enrich(context.Background(), EnrichmentQuery{Name: "Ivan"})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			person := &domain.Person{}
+			applyEnrichment(person, result, tt.provider)
+			if len(person.Provenance) != 1 {
+				t.Fatalf("provenance of %d fields, want only %s", len(person.Provenance), tt.field)
+			}
+			if got := person.Provenance[tt.field]; got == nil || got.Source != ProviderDataset {
+				t.Errorf("provenance of %s = %+v, want %s", tt.field, got, ProviderDataset)
+			}
+			if tt.field == domain.FieldGender && (person.GenderSource == nil || *person.GenderSource != ProviderDataset) {
+				t.Errorf("gender source = %v, want %s", person.GenderSource, ProviderDataset)
+			}
+		})
+	}
+}
+
+func TestNewDatasetEnricherErrors(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"missing.csv": "",
+		"names.txt":   "name\nIvan\n",
+		"broken.json": `[{"name": `,
+		"broken.csv":  "name,age\nIvan,old\n",
+	} {
+		path := filepath.Join(dir, name)
+		if content != "" {
+			writeDataset(t, path, content, time.Now())
+		}
+		if _, err := NewDatasetEnricher(path, nil, testLogger()); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestDatasetEnricherWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "names.csv")
+	loaded := time.Now().Add(-time.Hour)
+	writeDataset(t, path, "name,age\nIvan,47\nAnna,39\n", loaded)
+
+	e, err := NewDatasetEnricher(path, nil, testLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go e.Watch(ctx, 5*time.Millisecond)
+
+	// A broken file is reported and the previous data is kept
+	changed := loaded.Add(time.Minute)
+	writeDataset(t, path, "name,age\nIvan,47\n\"An", changed)
+	time.Sleep(50 * time.Millisecond)
+	if result, _ := e.Enrich(ctx, EnrichmentQuery{Name: "Anna"}); e.Len() != 2 || result.Age != 39 {
+		t.Fatalf("previous dataset lost: %d entries, Anna %+v", e.Len(), result)
+	}
+
+	// Completing the file without a new modification time is still picked up
+	writeDataset(t, path, "name,age\nIvan,47\nAnna,39\nOleg,52\n", changed)
+	deadline := time.Now().Add(time.Second)
+	for e.Len() != 3 {
+		if time.Now().After(deadline) {
+			t.Fatal("the completed dataset was not reloaded")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if result, _ := e.Enrich(ctx, EnrichmentQuery{Name: "Oleg"}); result.Age != 52 {
+		t.Errorf("Oleg = %+v, want age 52", result)
+	}
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"os"
+	"person-service/internal/config"
+	"person-service/internal/domain"
+	"person-service/internal/repository"
+)
+
+type DuplicateServiceInterface interface {
+	// FindDuplicates returns pairs of people that look like the same person. Zero fields of match
+	// take the configured defaults.
+	FindDuplicates(ctx context.Context, match domain.DuplicateMatch) ([]*domain.DuplicatePair, error)
+	// Merge merges the duplicate into the survivor and returns the updated survivor with the merge record
+	Merge(ctx context.Context, survivorID, duplicateID int64) (*domain.Person, *domain.PersonMerge, error)
+	// GetMerges returns the merges into the person, newest first
+	GetMerges(ctx context.Context, survivorID int64) ([]*domain.PersonMerge, error)
+}
+
+type DuplicateService struct {
+	people   repository.PersonRepositoryInterface
+	merges   repository.PersonMergeRepositoryInterface
+	defaults config.DuplicateConfig
+	log      *logrus.Logger
+}
+
+func NewDuplicateService(
+	people repository.PersonRepositoryInterface,
+	merges repository.PersonMergeRepositoryInterface,
+	defaults config.DuplicateConfig,
+	log *logrus.Logger,
+) DuplicateServiceInterface {
+	if log == nil {
+		log = logrus.New()
+		log.SetFormatter(&logrus.JSONFormatter{})
+		log.SetOutput(os.Stdout)
+		log.SetLevel(logrus.DebugLevel)
+	}
+	return &DuplicateService{
+		people:   people,
+		merges:   merges,
+		defaults: defaults,
+		log:      log,
+	}
+}
+
+func (s *DuplicateService) FindDuplicates(ctx context.Context, match domain.DuplicateMatch) ([]*domain.DuplicatePair, error) {
+	if len(match.Fields) == 0 {
+		match.Fields = s.defaults.Fields
+	}
+	if match.MinSimilarity <= 0 {
+		match.MinSimilarity = s.defaults.MinSimilarity
+	}
+	if match.Limit < 1 {
+		match.Limit = 10
+	}
+	s.log.WithFields(logrus.Fields{
+		"fields":         match.Fields,
+		"min_similarity": match.MinSimilarity,
+		"limit":          match.Limit,
+		"offset":         match.Offset,
+	}).Debug("Finding duplicates")
+
+	pairs, err := s.merges.FindDuplicates(ctx, match)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to find duplicates")
+		return nil, fmt.Errorf("failed to find duplicates: %w", err)
+	}
+	return pairs, nil
+}
+
+func (s *DuplicateService) Merge(ctx context.Context, survivorID, duplicateID int64) (*domain.Person, *domain.PersonMerge, error) {
+	merge, err := s.merges.Merge(ctx, survivorID, duplicateID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			s.log.Warnf("Person %d or %d not found for merge", survivorID, duplicateID)
+			return nil, nil, err
+		}
+		s.log.WithError(err).Errorf("Failed to merge person %d into %d", duplicateID, survivorID)
+		return nil, nil, fmt.Errorf("failed to merge people: %w", err)
+	}
+
+	survivor, err := s.people.GetById(ctx, survivorID, false)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get merged person: %w", err)
+	}
+	return survivor, merge, nil
+}
+
+func (s *DuplicateService) GetMerges(ctx context.Context, survivorID int64) ([]*domain.PersonMerge, error) {
+	if _, err := s.people.GetById(ctx, survivorID, false); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get person: %w", err)
+	}
+	merges, err := s.merges.ListBySurvivor(ctx, survivorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get merges: %w", err)
+	}
+	return merges, nil
+}
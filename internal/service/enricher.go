@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"person-service/internal/domain"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Enricher is a single source of person data, e.g. one external API.
+// Implementations fill only the fields they know about and leave the rest empty.
+type Enricher interface {
+	Name() string
+	// Fields lists the person fields the enricher can fill, see domain.EnrichableFields
+	Fields() []string
+	Enrich(ctx context.Context, query EnrichmentQuery) (*EnrichmentResult, error)
+}
+
+// BatchEnricher is implemented by enrichers that can answer several queries with fewer calls.
+// Results and errors are positional: for every query exactly one of them is set.
+type BatchEnricher interface {
+	Enricher
+	EnrichBatch(ctx context.Context, queries []EnrichmentQuery) ([]*EnrichmentResult, []error)
+}
+
+type EnrichmentQuery struct {
+	Name       string
+	Surname    string
+	Patronymic string
+	// CountryID is an optional ISO 3166-1 alpha-2 hint to localize the predictions
+	CountryID string
+}
+
+// EnricherRegistry keeps the known enrichers and the order in which the active ones are applied.
+// Earlier enrichers take precedence: a field filled by one is not overwritten by the next.
+type EnricherRegistry struct {
+	mu        sync.RWMutex
+	enrichers map[string]Enricher
+	order     []string
+}
+
+func NewEnricherRegistry() *EnricherRegistry {
+	return &EnricherRegistry{
+		enrichers: make(map[string]Enricher),
+	}
+}
+
+// Register adds an enricher to the end of the active list.
+// Registering a name that already exists replaces that enricher in place.
+func (r *EnricherRegistry) Register(e Enricher) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.enrichers[e.Name()]; !ok {
+		r.order = append(r.order, e.Name())
+	}
+	r.enrichers[e.Name()] = e
+}
+
+func (r *EnricherRegistry) Remove(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.enrichers, name)
+	for i, n := range r.order {
+		if n == name {
+			r.order = append(r.order[:i:i], r.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// Use restricts the active enrichers to names, in the given order.
+func (r *EnricherRegistry) Use(names ...string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	order := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if _, ok := r.enrichers[name]; !ok {
+			return fmt.Errorf("unknown enrichment provider: %s", name)
+		}
+		if seen[name] {
+			return fmt.Errorf("duplicate enrichment provider: %s", name)
+		}
+		seen[name] = true
+		order = append(order, name)
+	}
+	r.order = order
+	return nil
+}
+
+// Get returns the active enricher registered under name
+func (r *EnricherRegistry) Get(name string) (Enricher, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, n := range r.order {
+		if n == name {
+			return r.enrichers[name], true
+		}
+	}
+	return nil, false
+}
+
+// Names returns the names of the active enrichers in precedence order.
+func (r *EnricherRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]string(nil), r.order...)
+}
+
+// ProvidersFor returns the names of the active enrichers that fill any of fields, in precedence order.
+func (r *EnricherRegistry) ProvidersFor(fields []string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var names []string
+	for _, name := range r.order {
+		if slices.ContainsFunc(r.enrichers[name].Fields(), func(f string) bool { return slices.Contains(fields, f) }) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Enrichers returns the active enrichers in precedence order.
+func (r *EnricherRegistry) Enrichers() []Enricher {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	enrichers := make([]Enricher, 0, len(r.order))
+	for _, name := range r.order {
+		enrichers = append(enrichers, r.enrichers[name])
+	}
+	return enrichers
+}
+
+// enrichBatch runs queries through e, in as few calls as e supports.
+// Enrichers without batch support are queried concurrently one by one.
+func enrichBatch(ctx context.Context, e Enricher, queries []EnrichmentQuery) ([]*EnrichmentResult, []error) {
+	if be, ok := e.(BatchEnricher); ok {
+		return be.EnrichBatch(ctx, queries)
+	}
+
+	results := make([]*EnrichmentResult, len(queries))
+	errs := make([]error, len(queries))
+	var wg sync.WaitGroup
+	for i, query := range queries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = e.Enrich(ctx, query)
+		}()
+	}
+	wg.Wait()
+	return results, errs
+}
+
+// applyEnrichment copies the fields of result from provider that are still empty on person.
+// Their provenance is result.Source when another provider answered for this one.
+func applyEnrichment(person *domain.Person, result *EnrichmentResult, provider string) {
+	if result == nil {
+		return
+	}
+	if result.Source != "" {
+		provider = result.Source
+	}
+	var localization *string
+	if result.Localization != "" {
+		localization = &result.Localization
+	}
+	now := time.Now().UTC()
+	setProvenance := func(field, source string, confidence *float64) {
+		if person.Provenance == nil {
+			person.Provenance = make(map[string]*domain.FieldProvenance)
+		}
+		person.Provenance[field] = &domain.FieldProvenance{Source: source, Confidence: confidence, FetchedAt: now}
+	}
+	if person.Age == 0 && result.Age != 0 {
+		person.Age = result.Age
+		person.AgeLocalization = localization
+		setProvenance(domain.FieldAge, provider, nil)
+	}
+	if person.Gender == "" && result.Gender != "" {
+		person.Gender = result.Gender
+		person.GenderProbability = result.GenderProbability
+		person.GenderCount = result.GenderCount
+		person.GenderLocalization = localization
+		source := provider
+		if result.GenderSource != "" {
+			source = result.GenderSource
+		}
+		person.GenderSource = &source
+		setProvenance(domain.FieldGender, source, result.GenderProbability)
+	}
+	if person.Nationality == "" && result.Nationality != "" {
+		person.Nationality = result.Nationality
+		person.Nationalities = result.Nationalities
+		if len(result.Nationalities) > 0 {
+			person.NationalityProbability = &result.Nationalities[0].Probability
+		}
+		setProvenance(domain.FieldNationality, provider, person.NationalityProbability)
+	}
+}
+
+// WithFallback returns primary with fallback answering the queries primary failed on.
+// Only the fields of primary are taken from fallback, and a fallback answer that fills none of them
+// leaves primary's error in place, so the job is retried against primary later.
+func WithFallback(primary, fallback Enricher) Enricher {
+	return &fallbackEnricher{Enricher: primary, fallback: fallback}
+}
+
+type fallbackEnricher struct {
+	Enricher
+	fallback Enricher
+}
+
+func (e *fallbackEnricher) Enrich(ctx context.Context, query EnrichmentQuery) (*EnrichmentResult, error) {
+	results, errs := e.EnrichBatch(ctx, []EnrichmentQuery{query})
+	return results[0], errs[0]
+}
+
+func (e *fallbackEnricher) EnrichBatch(ctx context.Context, queries []EnrichmentQuery) ([]*EnrichmentResult, []error) {
+	results, errs := enrichBatch(ctx, e.Enricher, queries)
+
+	var failed []int
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, i)
+		}
+	}
+	if len(failed) == 0 || ctx.Err() != nil {
+		return results, errs
+	}
+
+	retry := make([]EnrichmentQuery, len(failed))
+	for i, index := range failed {
+		retry[i] = queries[index]
+	}
+	fallbackResults, fallbackErrs := enrichBatch(ctx, e.fallback, retry)
+	for i, index := range failed {
+		if fallbackErrs[i] != nil {
+			continue
+		}
+		if result := restrictResult(fallbackResults[i], e.Fields()); result != nil {
+			if result.Source == "" {
+				result.Source = e.fallback.Name()
+			}
+			results[index], errs[index] = result, nil
+		}
+	}
+	return results, errs
+}
+
+// restrictResult keeps the fields of result listed in fields. It returns nil when none of them is filled.
+func restrictResult(result *EnrichmentResult, fields []string) *EnrichmentResult {
+	if result == nil {
+		return nil
+	}
+	restricted := &EnrichmentResult{Localization: result.Localization, Source: result.Source}
+	filled := false
+	if slices.Contains(fields, domain.FieldAge) && result.Age != 0 {
+		restricted.Age = result.Age
+		filled = true
+	}
+	if slices.Contains(fields, domain.FieldGender) && result.Gender != "" {
+		restricted.Gender = result.Gender
+		restricted.GenderProbability = result.GenderProbability
+		restricted.GenderCount = result.GenderCount
+		restricted.GenderSource = result.GenderSource
+		filled = true
+	}
+	if slices.Contains(fields, domain.FieldNationality) && result.Nationality != "" {
+		restricted.Nationality = result.Nationality
+		restricted.Nationalities = result.Nationalities
+		filled = true
+	}
+	if !filled {
+		return nil
+	}
+	return restricted
+}
@@ -3,49 +3,245 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
+	"net/url"
+	"person-service/internal/config"
+	"person-service/internal/domain"
+	"person-service/internal/repository"
+	"slices"
+	"sort"
 	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	ProviderAgify       = "agify"
+	ProviderGenderize   = "genderize"
+	ProviderNationalize = "nationalize"
+
+	// MaxBatchNames is how many name[] parameters agify, genderize and nationalize accept per call
+	MaxBatchNames = 10
+)
+
 type EnrichmentClient struct {
-	client *http.Client
-	log    *logrus.Logger
+	providers map[string]*enrichmentProvider
+	cache     *EnrichmentCache
+	calls     repository.EnrichmentCallRepositoryInterface
+	// countryID localizes age and gender of queries without their own country hint
+	countryID string
+	log       *logrus.Logger
+}
+
+type enrichmentProvider struct {
+	cfg     config.ProviderConfig
+	client  *http.Client
+	limiter rateLimiter
 }
 
-func NewEnrichmentClient(log *logrus.Logger) *EnrichmentClient {
+// NewEnrichmentClient creates a client for the configured providers. cache and calls may be nil;
+// without calls the requests are not audited.
+func NewEnrichmentClient(
+	cfg *config.Config,
+	cache *EnrichmentCache,
+	calls repository.EnrichmentCallRepositoryInterface,
+	log *logrus.Logger,
+) *EnrichmentClient {
+	newProvider := func(pc config.ProviderConfig) *enrichmentProvider {
+		return &enrichmentProvider{
+			cfg:    pc,
+			client: &http.Client{Timeout: pc.Timeout},
+		}
+	}
 	return &EnrichmentClient{
-		client: &http.Client{Timeout: 5 * time.Second},
-		log:    log,
+		providers: map[string]*enrichmentProvider{
+			ProviderAgify:       newProvider(cfg.Agify),
+			ProviderGenderize:   newProvider(cfg.Genderize),
+			ProviderNationalize: newProvider(cfg.Nationalize),
+		},
+		cache:     cache,
+		calls:     calls,
+		countryID: cfg.EnrichmentCountryID,
+		log:       log,
 	}
 }
 
 type EnrichmentResult struct {
-	Age         int
+	Age               int
+	Gender            string
+	GenderProbability *float64
+	GenderCount       *int
+	// GenderSource names what decided Gender when it is not the provider itself, e.g. "patronymic"
+	GenderSource string
+	// Source names the provider that answered when it is not the one asked, e.g. a fallback dataset
+	Source string
+	// Localization is the country_id Age and Gender were predicted for, empty for global data
+	Localization string
+	Nationality  string
+	// Nationalities is the ranked distribution behind Nationality, most likely first
+	Nationalities []domain.CountryProbability
+	Errors        []error
+}
+
+type GenderPrediction struct {
 	Gender      string
-	Nationality string
-	Errors      []error
+	Probability float64
+	Count       int
 }
 
-func (c *EnrichmentClient) GetAge(ctx context.Context, name string) (int, error) {
-	resp, err := c.client.Get(fmt.Sprintf("https://api.agify.io/?name=%s", name))
+// get calls the provider's base URL with params and the API key. The request is cancelled together with ctx.
+func (c *EnrichmentClient) get(ctx context.Context, provider string, params url.Values) (*http.Response, error) {
+	p := c.providers[provider]
+	query := maps.Clone(params)
+	if p.cfg.APIKey != "" {
+		query.Set("apikey", p.cfg.APIKey)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.cfg.BaseURL+"/?"+query.Encode(), nil)
+	if err != nil {
+		return nil, redactURL(err, p.cfg.BaseURL+"/?"+params.Encode())
+	}
+	resp, err := p.client.Do(req)
 	if err != nil {
-		return 0, fmt.Errorf("agify request failed: %w", err)
+		return nil, redactURL(err, p.cfg.BaseURL+"/?"+params.Encode())
+	}
+	return resp, nil
+}
+
+// redactURL replaces the URL quoted by a request error, which carries the API key, with redacted.
+// Request errors end up in job errors, the call audit and the logs.
+func redactURL(err error, redacted string) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = redacted
+	}
+	return err
+}
+
+// call performs a request with retries and returns the validated JSON body. Every call is audited.
+func (c *EnrichmentClient) call(ctx context.Context, provider string, params url.Values) (body []byte, err error) {
+	started := time.Now()
+	status := 0
+	defer func() {
+		c.recordCall(ctx, provider, params, status, time.Since(started), body, err)
+	}()
+
+	resp, err := c.getWithRetry(ctx, provider, params)
+	if err != nil {
+		var statusErr *statusError
+		if errors.As(err, &statusErr) {
+			status = statusErr.status
+		}
+		return nil, err
 	}
 	defer resp.Body.Close()
+	status = resp.StatusCode
 
-	if resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("agify returned status: %d", resp.StatusCode)
+	body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s response: %w", provider, err)
 	}
+	if !json.Valid(body) {
+		return nil, fmt.Errorf("failed to parse %s response: invalid JSON", provider)
+	}
+	return body, nil
+}
 
-	body, err := io.ReadAll(resp.Body)
+// localizedParams adds the country_id parameter when a country is given
+func localizedParams(params url.Values, countryID string) url.Values {
+	if countryID != "" {
+		params.Set("country_id", countryID)
+	}
+	return params
+}
+
+// localizedCacheName keeps answers for different countries apart in the cache
+func localizedCacheName(name, countryID string) string {
+	if countryID == "" {
+		return name
+	}
+	return name + cacheCountrySeparator + countryID
+}
+
+// fetch returns the provider's raw JSON answer for name in countryID (global when empty),
+// consulting the cache before calling out
+func (c *EnrichmentClient) fetch(ctx context.Context, provider, name, countryID string) ([]byte, error) {
+	cacheName := localizedCacheName(name, countryID)
+	if c.cache != nil && !cacheBypassed(ctx) {
+		if payload, ok := c.cache.Get(ctx, provider, cacheName); ok {
+			return payload, nil
+		}
+	}
+
+	body, err := c.call(ctx, provider, localizedParams(url.Values{"name": {name}}, countryID))
 	if err != nil {
-		return 0, fmt.Errorf("failed to read agify response: %w", err)
+		return nil, err
 	}
 
+	if c.cache != nil {
+		c.cache.Set(ctx, provider, cacheName, body)
+	}
+	return body, nil
+}
+
+// fetchBatch returns the provider's raw JSON answer in countryID per normalized name. Cached names are not asked for,
+// the rest is requested in chunks of MaxBatchNames. Names of failed chunks are missing from the result
+// and the first failure is returned alongside the answers that did arrive.
+func (c *EnrichmentClient) fetchBatch(ctx context.Context, provider string, names []string, countryID string) (map[string][]byte, error) {
+	answers := make(map[string][]byte, len(names))
+	var missing []string
+	for _, name := range names {
+		name = normalizeCacheName(name)
+		if _, seen := answers[name]; seen || slices.Contains(missing, name) {
+			continue
+		}
+		if c.cache != nil && !cacheBypassed(ctx) {
+			if payload, ok := c.cache.Get(ctx, provider, localizedCacheName(name, countryID)); ok {
+				answers[name] = payload
+				continue
+			}
+		}
+		missing = append(missing, name)
+	}
+
+	var firstErr error
+	for chunk := range slices.Chunk(missing, MaxBatchNames) {
+		body, err := c.call(ctx, provider, localizedParams(url.Values{"name[]": chunk}, countryID))
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		var items []json.RawMessage
+		if err := json.Unmarshal(body, &items); err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to parse %s response: %w", provider, err)
+			}
+			continue
+		}
+		for _, item := range items {
+			var echo struct {
+				Name string `json:"name"`
+			}
+			if err := json.Unmarshal(item, &echo); err != nil {
+				continue
+			}
+			name := normalizeCacheName(echo.Name)
+			answers[name] = item
+			if c.cache != nil {
+				c.cache.Set(ctx, provider, localizedCacheName(name, countryID), item)
+			}
+		}
+	}
+	return answers, firstErr
+}
+
+func parseAge(body []byte) (int, error) {
 	var data struct{ Age int }
 	if err := json.Unmarshal(body, &data); err != nil {
 		return 0, fmt.Errorf("failed to parse agify response: %w", err)
@@ -53,55 +249,283 @@ func (c *EnrichmentClient) GetAge(ctx context.Context, name string) (int, error)
 	return data.Age, nil
 }
 
-func (c *EnrichmentClient) GetGender(ctx context.Context, name string) (string, error) {
-	resp, err := c.client.Get(fmt.Sprintf("https://api.genderize.io/?name=%s", name))
-	if err != nil {
-		return "", fmt.Errorf("genderize request failed: %w", err)
+func parseGender(body []byte) (*GenderPrediction, error) {
+	var data struct {
+		Gender      *string `json:"gender"`
+		Probability float64 `json:"probability"`
+		Count       int     `json:"count"`
 	}
-	defer resp.Body.Close()
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse genderize response: %w", err)
+	}
+	if data.Gender == nil {
+		return &GenderPrediction{Count: data.Count}, nil
+	}
+	return &GenderPrediction{
+		Gender:      *data.Gender,
+		Probability: data.Probability,
+		Count:       data.Count,
+	}, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("genderize returned status: %d", resp.StatusCode)
+func parseNationality(body []byte) ([]domain.CountryProbability, error) {
+	var data struct {
+		Country []domain.CountryProbability `json:"country"`
+	}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse nationalize response: %w", err)
+	}
+	sort.SliceStable(data.Country, func(i, j int) bool {
+		return data.Country[i].Probability > data.Country[j].Probability
+	})
+	return data.Country, nil
+}
+
+// parseBatch applies parse to every answer of a batch. Unparsable answers are left out.
+func parseBatch[T any](answers map[string][]byte, err error, parse func([]byte) (T, error)) (map[string]T, error) {
+	parsed := make(map[string]T, len(answers))
+	for name, body := range answers {
+		value, parseErr := parse(body)
+		if parseErr != nil {
+			if err == nil {
+				err = parseErr
+			}
+			continue
+		}
+		parsed[name] = value
 	}
+	return parsed, err
+}
 
-	body, err := io.ReadAll(resp.Body)
+// GetAge returns the age agify predicts for name in countryID, or globally when countryID is empty
+func (c *EnrichmentClient) GetAge(ctx context.Context, name, countryID string) (int, error) {
+	body, err := c.fetch(ctx, ProviderAgify, name, countryID)
 	if err != nil {
-		return "", fmt.Errorf("failed to read genderize response: %w", err)
+		return 0, err
 	}
+	return parseAge(body)
+}
 
-	var data struct{ Gender string }
-	if err := json.Unmarshal(body, &data); err != nil {
-		return "", fmt.Errorf("failed to parse genderize response: %w", err)
+// GetGender returns the gender genderize predicts for name in countryID, or globally when countryID is empty
+func (c *EnrichmentClient) GetGender(ctx context.Context, name, countryID string) (*GenderPrediction, error) {
+	body, err := c.fetch(ctx, ProviderGenderize, name, countryID)
+	if err != nil {
+		return nil, err
 	}
-	return data.Gender, nil
+	return parseGender(body)
 }
 
-func (c *EnrichmentClient) GetNationality(ctx context.Context, name string) (string, error) {
-	resp, err := c.client.Get(fmt.Sprintf("https://api.nationalize.io/?name=%s", name))
+// GetNationality returns the countries nationalize predicts for name, most likely first
+func (c *EnrichmentClient) GetNationality(ctx context.Context, name string) ([]domain.CountryProbability, error) {
+	body, err := c.fetch(ctx, ProviderNationalize, name, "")
 	if err != nil {
-		return "", fmt.Errorf("nationalize request failed: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
+	return parseNationality(body)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("nationalize returned status: %d", resp.StatusCode)
+// GetAgeBatch returns the age in countryID per normalized name, using as few agify calls as possible.
+// On partial failure it returns the ages it got together with the first error.
+func (c *EnrichmentClient) GetAgeBatch(ctx context.Context, names []string, countryID string) (map[string]int, error) {
+	answers, err := c.fetchBatch(ctx, ProviderAgify, names, countryID)
+	return parseBatch(answers, err, parseAge)
+}
+
+// GetGenderBatch is the genderize counterpart of GetAgeBatch
+func (c *EnrichmentClient) GetGenderBatch(ctx context.Context, names []string, countryID string) (map[string]*GenderPrediction, error) {
+	answers, err := c.fetchBatch(ctx, ProviderGenderize, names, countryID)
+	return parseBatch(answers, err, parseGender)
+}
+
+// GetNationalityBatch is the nationalize counterpart of GetAgeBatch
+func (c *EnrichmentClient) GetNationalityBatch(ctx context.Context, names []string) (map[string][]domain.CountryProbability, error) {
+	answers, err := c.fetchBatch(ctx, ProviderNationalize, names, "")
+	return parseBatch(answers, err, parseNationality)
+}
+
+// Enrichers exposes each external API of the client as a separate Enricher.
+func (c *EnrichmentClient) Enrichers() []Enricher {
+	return []Enricher{
+		&agifyEnricher{client: c},
+		&genderizeEnricher{client: c},
+		&nationalizeEnricher{client: c},
 	}
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read nationalize response: %w", err)
+func queryNames(queries []EnrichmentQuery) []string {
+	names := make([]string, len(queries))
+	for i, query := range queries {
+		names[i] = query.Name
 	}
+	return names
+}
 
-	var data struct {
-		Country []struct {
-			CountryID string `json:"country_id"`
-		} `json:"country"`
+// batchResults lines up per-name answers with queries. Queries without an answer get err,
+// or a "no answer" error when the whole batch succeeded.
+func batchResults[T any](provider string, queries []EnrichmentQuery, answers map[string]T, err error, result func(T) *EnrichmentResult) ([]*EnrichmentResult, []error) {
+	results := make([]*EnrichmentResult, len(queries))
+	errs := make([]error, len(queries))
+	for i, query := range queries {
+		answer, ok := answers[normalizeCacheName(query.Name)]
+		switch {
+		case ok:
+			results[i] = result(answer)
+		case err != nil:
+			errs[i] = err
+		default:
+			errs[i] = fmt.Errorf("%s returned no answer for %s", provider, query.Name)
+		}
 	}
-	if err := json.Unmarshal(body, &data); err != nil {
-		return "", fmt.Errorf("failed to parse nationalize response: %w", err)
+	return results, errs
+}
+
+// localization returns the country the query's age and gender are predicted for
+func (c *EnrichmentClient) localization(query EnrichmentQuery) string {
+	if query.CountryID != "" {
+		return query.CountryID
+	}
+	return c.countryID
+}
+
+// enrichLocalized splits queries by localization, since a batch call takes a single country_id,
+// and puts the results of each group back in query order
+func (c *EnrichmentClient) enrichLocalized(
+	queries []EnrichmentQuery,
+	enrich func(countryID string, queries []EnrichmentQuery) ([]*EnrichmentResult, []error),
+) ([]*EnrichmentResult, []error) {
+	groups := make(map[string][]int)
+	var countries []string
+	for i, query := range queries {
+		countryID := c.localization(query)
+		if _, ok := groups[countryID]; !ok {
+			countries = append(countries, countryID)
+		}
+		groups[countryID] = append(groups[countryID], i)
+	}
+
+	results := make([]*EnrichmentResult, len(queries))
+	errs := make([]error, len(queries))
+	for _, countryID := range countries {
+		indexes := groups[countryID]
+		group := make([]EnrichmentQuery, len(indexes))
+		for i, index := range indexes {
+			group[i] = queries[index]
+		}
+		groupResults, groupErrs := enrich(countryID, group)
+		for i, index := range indexes {
+			results[index], errs[index] = groupResults[i], groupErrs[i]
+		}
+	}
+	return results, errs
+}
+
+func ageResult(age int, countryID string) *EnrichmentResult {
+	return &EnrichmentResult{Age: age, Localization: countryID}
+}
+
+func genderResult(prediction *GenderPrediction, countryID string) *EnrichmentResult {
+	if prediction.Gender == "" {
+		return &EnrichmentResult{}
+	}
+	return &EnrichmentResult{
+		Gender:            prediction.Gender,
+		GenderProbability: &prediction.Probability,
+		GenderCount:       &prediction.Count,
+		Localization:      countryID,
+	}
+}
+
+func nationalityResult(countries []domain.CountryProbability) *EnrichmentResult {
+	if len(countries) == 0 {
+		return &EnrichmentResult{}
+	}
+	return &EnrichmentResult{
+		Nationality:   countries[0].CountryID,
+		Nationalities: countries,
+	}
+}
+
+type agifyEnricher struct {
+	client *EnrichmentClient
+}
+
+func (e *agifyEnricher) Name() string {
+	return ProviderAgify
+}
+
+func (e *agifyEnricher) Fields() []string {
+	return []string{domain.FieldAge}
+}
+
+func (e *agifyEnricher) Enrich(ctx context.Context, query EnrichmentQuery) (*EnrichmentResult, error) {
+	countryID := e.client.localization(query)
+	age, err := e.client.GetAge(ctx, query.Name, countryID)
+	if err != nil {
+		return nil, err
+	}
+	return ageResult(age, countryID), nil
+}
+
+func (e *agifyEnricher) EnrichBatch(ctx context.Context, queries []EnrichmentQuery) ([]*EnrichmentResult, []error) {
+	return e.client.enrichLocalized(queries, func(countryID string, queries []EnrichmentQuery) ([]*EnrichmentResult, []error) {
+		ages, err := e.client.GetAgeBatch(ctx, queryNames(queries), countryID)
+		return batchResults(ProviderAgify, queries, ages, err, func(age int) *EnrichmentResult {
+			return ageResult(age, countryID)
+		})
+	})
+}
+
+type genderizeEnricher struct {
+	client *EnrichmentClient
+}
+
+func (e *genderizeEnricher) Name() string {
+	return ProviderGenderize
+}
+
+func (e *genderizeEnricher) Fields() []string {
+	return []string{domain.FieldGender}
+}
+
+func (e *genderizeEnricher) Enrich(ctx context.Context, query EnrichmentQuery) (*EnrichmentResult, error) {
+	countryID := e.client.localization(query)
+	prediction, err := e.client.GetGender(ctx, query.Name, countryID)
+	if err != nil {
+		return nil, err
 	}
-	if len(data.Country) == 0 {
-		return "", nil
+	return genderResult(prediction, countryID), nil
+}
+
+func (e *genderizeEnricher) EnrichBatch(ctx context.Context, queries []EnrichmentQuery) ([]*EnrichmentResult, []error) {
+	return e.client.enrichLocalized(queries, func(countryID string, queries []EnrichmentQuery) ([]*EnrichmentResult, []error) {
+		predictions, err := e.client.GetGenderBatch(ctx, queryNames(queries), countryID)
+		return batchResults(ProviderGenderize, queries, predictions, err, func(prediction *GenderPrediction) *EnrichmentResult {
+			return genderResult(prediction, countryID)
+		})
+	})
+}
+
+type nationalizeEnricher struct {
+	client *EnrichmentClient
+}
+
+func (e *nationalizeEnricher) Name() string {
+	return ProviderNationalize
+}
+
+func (e *nationalizeEnricher) Fields() []string {
+	return []string{domain.FieldNationality}
+}
+
+func (e *nationalizeEnricher) Enrich(ctx context.Context, query EnrichmentQuery) (*EnrichmentResult, error) {
+	countries, err := e.client.GetNationality(ctx, query.Name)
+	if err != nil {
+		return nil, err
 	}
-	return data.Country[0].CountryID, nil
+	return nationalityResult(countries), nil
+}
+
+func (e *nationalizeEnricher) EnrichBatch(ctx context.Context, queries []EnrichmentQuery) ([]*EnrichmentResult, []error) {
+	countries, err := e.client.GetNationalityBatch(ctx, queryNames(queries))
+	return batchResults(ProviderNationalize, queries, countries, err, nationalityResult)
 }
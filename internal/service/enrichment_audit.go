@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"person-service/internal/domain"
+	"time"
+)
+
+// callAuditTimeout bounds storing one call record, which also runs after the enrichment deadline has passed
+const callAuditTimeout = 5 * time.Second
+
+type callPeopleKey struct{}
+
+// WithCallPeople attributes the enrichment calls made with ctx to people.
+// people maps each name as it is sent to the providers to the IDs of the people queried with it.
+func WithCallPeople(ctx context.Context, people map[string][]int64) context.Context {
+	return context.WithValue(ctx, callPeopleKey{}, people)
+}
+
+// callPersonIDs returns the people a call asking for names was made for
+func callPersonIDs(ctx context.Context, names []string) []int64 {
+	people, _ := ctx.Value(callPeopleKey{}).(map[string][]int64)
+	var ids []int64
+	for _, name := range names {
+		ids = append(ids, people[normalizeCacheName(name)]...)
+	}
+	return ids
+}
+
+// recordCall stores the outcome of a provider call. Audit failures are logged and never fail enrichment.
+func (c *EnrichmentClient) recordCall(
+	ctx context.Context,
+	provider string,
+	params url.Values,
+	status int,
+	latency time.Duration,
+	body []byte,
+	callErr error,
+) {
+	if c.calls == nil {
+		return
+	}
+
+	// params never carry the API key, get adds it to its own copy of them
+	names := params["name"]
+	if batch, ok := params["name[]"]; ok {
+		names = batch
+	}
+	call := &domain.EnrichmentCall{
+		Provider:  provider,
+		Names:     names,
+		PersonIDs: callPersonIDs(ctx, names),
+		LatencyMs: latency.Milliseconds(),
+		Response:  body,
+	}
+	if countryID := params.Get("country_id"); countryID != "" {
+		call.CountryID = &countryID
+	}
+	if status != 0 {
+		call.StatusCode = &status
+	}
+	if callErr != nil {
+		message := callErr.Error()
+		call.Error = &message
+	}
+
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), callAuditTimeout)
+	defer cancel()
+	if err := c.calls.Record(recordCtx, call); err != nil {
+		c.log.WithError(err).WithField("provider", provider).Warn("Failed to record enrichment call")
+	}
+}
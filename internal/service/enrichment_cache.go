@@ -0,0 +1,215 @@
+package service
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"person-service/internal/config"
+	"person-service/internal/domain"
+	"person-service/internal/repository"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type EnrichmentCacheInterface interface {
+	Purge(ctx context.Context, provider, name string) (int64, error)
+	Stats() domain.EnrichmentCacheStats
+}
+
+// EnrichmentCache keeps raw provider answers keyed by provider and normalized name.
+// Lookups go to an in-process LRU first and fall back to the database table.
+type EnrichmentCache struct {
+	repo repository.EnrichmentCacheRepositoryInterface
+	ttl  time.Duration
+	size int
+	log  *logrus.Logger
+
+	mu    sync.Mutex
+	lru   *list.List
+	items map[cacheKey]*list.Element
+
+	memoryHits atomic.Int64
+	storeHits  atomic.Int64
+	misses     atomic.Int64
+}
+
+type cacheKey struct {
+	provider string
+	name     string
+}
+
+type cacheItem struct {
+	key       cacheKey
+	payload   []byte
+	expiresAt time.Time
+}
+
+// NewEnrichmentCache creates a cache backed by repo. With a nil repo only the LRU is used.
+func NewEnrichmentCache(repo repository.EnrichmentCacheRepositoryInterface, cfg config.CacheConfig, log *logrus.Logger) *EnrichmentCache {
+	return &EnrichmentCache{
+		repo:  repo,
+		ttl:   cfg.TTL,
+		size:  cfg.Size,
+		log:   log,
+		lru:   list.New(),
+		items: make(map[cacheKey]*list.Element),
+	}
+}
+
+type bypassCacheKey struct{}
+
+// WithoutCache makes enrichment calls made with ctx ignore cached answers. Fresh answers are still cached.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassCacheKey{}, true)
+}
+
+func cacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(bypassCacheKey{}).(bool)
+	return bypass
+}
+
+func normalizeCacheName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// cacheCountrySeparator separates the name from the country_id in the cache names of localized answers
+const cacheCountrySeparator = "|"
+
+// cacheNameMatches reports whether the cache name belongs to name, globally or in any country
+func cacheNameMatches(cacheName, name string) bool {
+	return cacheName == name || strings.HasPrefix(cacheName, name+cacheCountrySeparator)
+}
+
+func (c *EnrichmentCache) Get(ctx context.Context, provider, name string) ([]byte, bool) {
+	if c.ttl <= 0 {
+		return nil, false
+	}
+	key := cacheKey{provider: provider, name: normalizeCacheName(name)}
+
+	if payload, ok := c.getMemory(key); ok {
+		c.memoryHits.Add(1)
+		return payload, true
+	}
+
+	if c.repo != nil {
+		entry, err := c.repo.Get(ctx, key.provider, key.name)
+		switch {
+		case err == nil:
+			c.storeHits.Add(1)
+			c.setMemory(key, entry.Payload, entry.FetchedAt.Add(c.ttl))
+			return entry.Payload, true
+		case !errors.Is(err, repository.ErrNotFound):
+			c.log.WithError(err).WithField("provider", provider).Warn("Enrichment cache lookup failed")
+		}
+	}
+
+	c.misses.Add(1)
+	return nil, false
+}
+
+func (c *EnrichmentCache) Set(ctx context.Context, provider, name string, payload []byte) {
+	if c.ttl <= 0 {
+		return
+	}
+	key := cacheKey{provider: provider, name: normalizeCacheName(name)}
+	now := time.Now().UTC()
+	c.setMemory(key, payload, now.Add(c.ttl))
+
+	if c.repo == nil {
+		return
+	}
+	err := c.repo.Set(ctx, &domain.EnrichmentCacheEntry{
+		Name:      key.name,
+		Provider:  key.provider,
+		Payload:   payload,
+		FetchedAt: now,
+		TTL:       c.ttl,
+	})
+	if err != nil {
+		c.log.WithError(err).WithField("provider", provider).Warn("Failed to store enrichment cache entry")
+	}
+}
+
+// Purge drops entries matching provider and name from both layers; empty values match everything.
+// A name matches its global and all of its localized entries.
+// It returns the number of entries dropped from memory plus the number deleted from the database.
+func (c *EnrichmentCache) Purge(ctx context.Context, provider, name string) (int64, error) {
+	name = normalizeCacheName(name)
+
+	c.mu.Lock()
+	var purged int64
+	for key, elem := range c.items {
+		if (provider == "" || key.provider == provider) && (name == "" || cacheNameMatches(key.name, name)) {
+			c.lru.Remove(elem)
+			delete(c.items, key)
+			purged++
+		}
+	}
+	c.mu.Unlock()
+
+	if c.repo == nil {
+		return purged, nil
+	}
+	stored, err := c.repo.Purge(ctx, provider, name)
+	if err != nil {
+		return purged, err
+	}
+	return purged + stored, nil
+}
+
+func (c *EnrichmentCache) Stats() domain.EnrichmentCacheStats {
+	c.mu.Lock()
+	entries := c.lru.Len()
+	c.mu.Unlock()
+
+	return domain.EnrichmentCacheStats{
+		MemoryHits: c.memoryHits.Load(),
+		StoreHits:  c.storeHits.Load(),
+		Misses:     c.misses.Load(),
+		Entries:    entries,
+	}
+}
+
+func (c *EnrichmentCache) getMemory(key cacheKey) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	item := elem.Value.(*cacheItem)
+	if time.Now().After(item.expiresAt) {
+		c.lru.Remove(elem)
+		delete(c.items, key)
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return item.payload, true
+}
+
+func (c *EnrichmentCache) setMemory(key cacheKey, payload []byte, expiresAt time.Time) {
+	if c.size <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		item := elem.Value.(*cacheItem)
+		item.payload = payload
+		item.expiresAt = expiresAt
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	c.items[key] = c.lru.PushFront(&cacheItem{key: key, payload: payload, expiresAt: expiresAt})
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheItem).key)
+	}
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"person-service/internal/config"
+	"person-service/internal/domain"
+	"person-service/internal/repository"
+	"testing"
+	"time"
+)
+
+func TestEnrichmentCachePurgeByName(t *testing.T) {
+	ctx := context.Background()
+	cache := NewEnrichmentCache(nil, config.CacheConfig{TTL: time.Hour, Size: 10}, testLogger())
+	for _, name := range []string{
+		"ivan",
+		localizedCacheName("ivan", "RU"),
+		localizedCacheName("ivan", "UA"),
+		"ivanka",
+		localizedCacheName("ivanka", "RU"),
+	} {
+		cache.Set(ctx, ProviderAgify, name, []byte(`{}`))
+	}
+	cache.Set(ctx, ProviderGenderize, localizedCacheName("ivan", "RU"), []byte(`{}`))
+
+	purged, err := cache.Purge(ctx, ProviderAgify, "Ivan")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if purged != 3 {
+		t.Errorf("purged %d entries, want 3", purged)
+	}
+	for _, name := range []string{"ivan", localizedCacheName("ivan", "RU"), localizedCacheName("ivan", "UA")} {
+		if _, ok := cache.Get(ctx, ProviderAgify, name); ok {
+			t.Errorf("%s is still cached", name)
+		}
+	}
+	for _, name := range []string{"ivanka", localizedCacheName("ivanka", "RU")} {
+		if _, ok := cache.Get(ctx, ProviderAgify, name); !ok {
+			t.Errorf("%s was purged", name)
+		}
+	}
+	if _, ok := cache.Get(ctx, ProviderGenderize, localizedCacheName("ivan", "RU")); !ok {
+		t.Error("the entry of another provider was purged")
+	}
+}
+
+// storedCache is a cache repository holding purged entries of the database
+type storedCache struct {
+	purged int64
+}
+
+func (r *storedCache) Get(ctx context.Context, provider, name string) (*domain.EnrichmentCacheEntry, error) {
+	return nil, repository.ErrNotFound
+}
+
+func (r *storedCache) Set(ctx context.Context, entry *domain.EnrichmentCacheEntry) error {
+	return nil
+}
+
+func (r *storedCache) Purge(ctx context.Context, provider, name string) (int64, error) {
+	return r.purged, nil
+}
+
+func TestEnrichmentCachePurgeCountsBothLayers(t *testing.T) {
+	ctx := context.Background()
+	cache := NewEnrichmentCache(&storedCache{purged: 5}, config.CacheConfig{TTL: time.Hour, Size: 10}, testLogger())
+	cache.Set(ctx, ProviderAgify, "ivan", []byte(`{}`))
+	cache.Set(ctx, ProviderGenderize, "ivan", []byte(`{}`))
+
+	purged, err := cache.Purge(ctx, "", "ivan")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if purged != 7 {
+		t.Errorf("purged %d entries, want 2 from memory and 5 from the database", purged)
+	}
+}
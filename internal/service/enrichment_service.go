@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"os"
+	"person-service/internal/domain"
+	"person-service/internal/names"
+	"person-service/internal/repository"
+	"sync"
+)
+
+var ErrNoProviders = errors.New("no active enrichment provider fills the requested fields")
+
+type EnrichmentServiceInterface interface {
+	// EnrichPerson queues a refresh of fields (all enrichable fields when empty) for one person.
+	// Manually set fields are only refreshed with force.
+	EnrichPerson(ctx context.Context, id int64, fields []string, force bool) (*domain.EnrichmentJob, error)
+	// EnrichPeople queues a refresh of fields for every person matching filter in the background
+	EnrichPeople(ctx context.Context, filter domain.PersonFilter, fields []string, force bool) (*domain.EnrichmentBatch, error)
+	GetJob(ctx context.Context, id int64) (*domain.EnrichmentJob, error)
+	GetBatch(ctx context.Context, id int64) (*domain.EnrichmentBatch, error)
+	// GetHistory returns the latest limit provider calls made for the person, newest first
+	GetHistory(ctx context.Context, personID int64, limit int) ([]*domain.EnrichmentCall, error)
+	// Wait blocks until the batches EnrichPeople is queueing in the background are queued.
+	// Call it once the server no longer accepts requests.
+	Wait()
+}
+
+type EnrichmentService struct {
+	people    repository.PersonRepositoryInterface
+	jobs      repository.EnrichmentJobRepositoryInterface
+	calls     repository.EnrichmentCallRepositoryInterface
+	enrichers *EnricherRegistry
+	names     *names.Normalizer
+	log       *logrus.Logger
+	// enqueueing tracks the batches being queued in the background
+	enqueueing sync.WaitGroup
+}
+
+func NewEnrichmentService(
+	people repository.PersonRepositoryInterface,
+	jobs repository.EnrichmentJobRepositoryInterface,
+	calls repository.EnrichmentCallRepositoryInterface,
+	enrichers *EnricherRegistry,
+	normalizer *names.Normalizer,
+	log *logrus.Logger,
+) EnrichmentServiceInterface {
+	if log == nil {
+		log = logrus.New()
+		log.SetFormatter(&logrus.JSONFormatter{})
+		log.SetOutput(os.Stdout)
+		log.SetLevel(logrus.DebugLevel)
+	}
+	if normalizer == nil {
+		normalizer = &names.Normalizer{}
+	}
+	return &EnrichmentService{
+		people:    people,
+		jobs:      jobs,
+		calls:     calls,
+		enrichers: enrichers,
+		names:     normalizer,
+		log:       log,
+	}
+}
+
+func (s *EnrichmentService) EnrichPerson(ctx context.Context, id int64, fields []string, force bool) (*domain.EnrichmentJob, error) {
+	if len(fields) == 0 {
+		fields = domain.EnrichableFields
+	}
+	providers := s.enrichers.ProvidersFor(fields)
+	if len(providers) == 0 {
+		return nil, ErrNoProviders
+	}
+
+	if _, err := s.people.GetById(ctx, id, false); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			s.log.Warnf("Person with ID %d not found", id)
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get person: %w", err)
+	}
+
+	job := &domain.EnrichmentJob{
+		PersonID:  id,
+		Providers: providers,
+		Fields:    fields,
+		Refresh:   true,
+		Force:     force,
+	}
+	if err := s.jobs.Enqueue(ctx, job); err != nil {
+		s.log.Errorf("Failed to enqueue re-enrichment of person %d: %v", id, err)
+		return nil, fmt.Errorf("failed to enqueue enrichment: %w", err)
+	}
+	if err := s.people.SetEnrichmentStatus(ctx, id, domain.EnrichmentPending); err != nil {
+		s.log.WithError(err).Warnf("Failed to reset enrichment status of person %d", id)
+	}
+	s.log.WithFields(logrus.Fields{
+		"person_id": id,
+		"job_id":    job.ID,
+		"fields":    fields,
+		"force":     force,
+	}).Info("Queued re-enrichment")
+	return job, nil
+}
+
+func (s *EnrichmentService) EnrichPeople(ctx context.Context, filter domain.PersonFilter, fields []string, force bool) (*domain.EnrichmentBatch, error) {
+	if len(fields) == 0 {
+		fields = domain.EnrichableFields
+	}
+	providers := s.enrichers.ProvidersFor(fields)
+	if len(providers) == 0 {
+		return nil, ErrNoProviders
+	}
+
+	batch := &domain.EnrichmentBatch{Filters: searchFilter(s.names, filter), Fields: fields, Force: force}
+	if err := s.jobs.CreateBatch(ctx, batch); err != nil {
+		return nil, fmt.Errorf("failed to create enrichment batch: %w", err)
+	}
+
+	// Selecting and queueing the matching rows may take a while on big tables,
+	// so it runs detached from the request; progress is read from the batch resource.
+	pending := *batch
+	s.enqueueing.Add(1)
+	go func() {
+		defer s.enqueueing.Done()
+		ctx := context.WithoutCancel(ctx)
+		if err := s.jobs.EnqueueBatch(ctx, &pending, providers); err != nil {
+			s.log.WithError(err).Errorf("Failed to enqueue enrichment batch %d", batch.ID)
+			if err := s.jobs.FailBatch(ctx, batch.ID, err.Error()); err != nil {
+				s.log.WithError(err).Errorf("Failed to mark enrichment batch %d as failed", batch.ID)
+			}
+		}
+	}()
+
+	s.log.WithFields(logrus.Fields{
+		"batch_id": batch.ID,
+		"filters":  batch.Filters,
+		"fields":   fields,
+		"force":    force,
+	}).Info("Created enrichment batch")
+	return batch, nil
+}
+
+func (s *EnrichmentService) GetJob(ctx context.Context, id int64) (*domain.EnrichmentJob, error) {
+	job, err := s.jobs.GetById(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get enrichment job: %w", err)
+	}
+	return job, nil
+}
+
+func (s *EnrichmentService) GetBatch(ctx context.Context, id int64) (*domain.EnrichmentBatch, error) {
+	batch, err := s.jobs.GetBatch(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get enrichment batch: %w", err)
+	}
+	return batch, nil
+}
+
+func (s *EnrichmentService) GetHistory(ctx context.Context, personID int64, limit int) ([]*domain.EnrichmentCall, error) {
+	if _, err := s.people.GetById(ctx, personID, false); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get person: %w", err)
+	}
+	calls, err := s.calls.ListByPerson(ctx, personID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get enrichment history: %w", err)
+	}
+	return calls, nil
+}
+
+func (s *EnrichmentService) Wait() {
+	s.enqueueing.Wait()
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"person-service/internal/config"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEnrichmentClientKeepsAPIKeyOutOfErrors(t *testing.T) {
+	const key = "secret-key"
+	server := httptest.NewServer(http.NotFoundHandler())
+	baseURL := server.URL
+	server.Close()
+
+	provider := config.ProviderConfig{
+		BaseURL: baseURL,
+		Timeout: time.Second,
+		APIKey:  key,
+		Retry:   config.RetryConfig{MaxAttempts: 1},
+	}
+	client := NewEnrichmentClient(&config.Config{Agify: provider}, nil, nil, testLogger())
+
+	_, err := client.GetAge(context.Background(), "ivan", "")
+	if err == nil {
+		t.Fatal("expected an error from an unreachable provider")
+	}
+	if strings.Contains(err.Error(), key) {
+		t.Errorf("error %q contains the API key", err)
+	}
+	if !strings.Contains(err.Error(), "name=ivan") {
+		t.Errorf("error %q lost the request parameters", err)
+	}
+}
+
+func TestEnrichmentClientSendsAPIKey(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Query().Get("apikey")
+		_, _ = w.Write([]byte(`{"name":"ivan","age":47}`))
+	}))
+	defer server.Close()
+
+	provider := config.ProviderConfig{
+		BaseURL: server.URL,
+		Timeout: time.Second,
+		APIKey:  "secret-key",
+		Retry:   config.RetryConfig{MaxAttempts: 1},
+	}
+	client := NewEnrichmentClient(&config.Config{Agify: provider}, nil, nil, testLogger())
+
+	age, err := client.GetAge(context.Background(), "ivan", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if age != 47 || got != "secret-key" {
+		t.Errorf("got age %d with apikey %q", age, got)
+	}
+}
@@ -0,0 +1,308 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"person-service/internal/config"
+	"person-service/internal/domain"
+	"person-service/internal/repository"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EnrichmentWorker runs a pool of workers that take enrichment jobs off the Postgres queue
+// and fill in the person's age, gender and nationality. Each worker claims up to cfg.BatchSize jobs at a time.
+type EnrichmentWorker struct {
+	people    repository.PersonRepositoryInterface
+	jobs      repository.EnrichmentJobRepositoryInterface
+	enrichers *EnricherRegistry
+	cfg       config.JobConfig
+	log       *logrus.Logger
+}
+
+func NewEnrichmentWorker(
+	people repository.PersonRepositoryInterface,
+	jobs repository.EnrichmentJobRepositoryInterface,
+	enrichers *EnricherRegistry,
+	cfg config.JobConfig,
+	log *logrus.Logger,
+) *EnrichmentWorker {
+	if cfg.Workers < 1 {
+		cfg.Workers = 1
+	}
+	if cfg.MaxAttempts < 1 {
+		cfg.MaxAttempts = 1
+	}
+	if cfg.BatchSize < 1 {
+		cfg.BatchSize = 1
+	}
+	if cfg.Deadline <= 0 {
+		cfg.Deadline = config.DefaultEnrichmentDeadline
+	}
+	return &EnrichmentWorker{
+		people:    people,
+		jobs:      jobs,
+		enrichers: enrichers,
+		cfg:       cfg,
+		log:       log,
+	}
+}
+
+// Run starts cfg.Workers workers and blocks until ctx is cancelled and all of them have stopped
+func (w *EnrichmentWorker) Run(ctx context.Context) {
+	w.log.Infof("Starting %d enrichment workers", w.cfg.Workers)
+	var wg sync.WaitGroup
+	for i := 0; i < w.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.loop(ctx)
+		}()
+	}
+	wg.Wait()
+	w.log.Info("Enrichment workers stopped")
+}
+
+func (w *EnrichmentWorker) loop(ctx context.Context) {
+	for ctx.Err() == nil {
+		jobs, err := w.jobs.Claim(ctx, w.cfg.LockTimeout, w.cfg.BatchSize)
+		if err != nil {
+			if !errors.Is(err, repository.ErrNotFound) && ctx.Err() == nil {
+				w.log.WithError(err).Error("Failed to claim enrichment jobs")
+			}
+			_ = sleep(ctx, w.cfg.PollInterval)
+			continue
+		}
+		w.process(ctx, jobs)
+	}
+}
+
+// enrichmentTask is one claimed job on its way through the providers.
+// results and errs are positional with enrichers.
+type enrichmentTask struct {
+	job       *domain.EnrichmentJob
+	person    *domain.Person
+	query     EnrichmentQuery
+	enrichers []Enricher
+	results   []*EnrichmentResult
+	errs      []error
+	log       *logrus.Entry
+}
+
+// process enriches a group of claimed jobs. Each provider is called once for the whole group,
+// so the names of all jobs share batched provider calls.
+func (w *EnrichmentWorker) process(ctx context.Context, jobs []*domain.EnrichmentJob) {
+	tasks := make([]*enrichmentTask, 0, len(jobs))
+	for _, job := range jobs {
+		if task := w.prepare(ctx, job); task != nil {
+			tasks = append(tasks, task)
+		}
+	}
+
+	enrichCtx, cancel := context.WithTimeout(ctx, w.cfg.Deadline)
+	w.enrich(enrichCtx, tasks)
+	cancel()
+	if ctx.Err() != nil {
+		// Shutting down: the jobs are taken over again once their locks time out
+		return
+	}
+
+	for _, task := range tasks {
+		w.finish(ctx, task)
+	}
+}
+
+// prepare loads the job's person and active providers. It returns nil when the job is already settled.
+func (w *EnrichmentWorker) prepare(ctx context.Context, job *domain.EnrichmentJob) *enrichmentTask {
+	log := w.log.WithFields(logrus.Fields{
+		"job_id":    job.ID,
+		"person_id": job.PersonID,
+		"attempt":   job.Attempts,
+	})
+
+	person, err := w.people.GetById(ctx, job.PersonID, false)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			w.complete(ctx, job)
+			return nil
+		}
+		log.WithError(err).Error("Failed to load person for enrichment")
+		w.retryOrFail(ctx, job, job.Providers, err.Error(), person)
+		return nil
+	}
+
+	// Providers are asked with the normalized Latin keys, so "Дмитрий" is looked up as "dmitrii"
+	task := &enrichmentTask{
+		job:    job,
+		person: person,
+		query:  EnrichmentQuery{Name: person.NameNormalized, Surname: person.SurnameNormalized},
+		log:    log,
+	}
+	if person.PatronymicNormalized != nil {
+		task.query.Patronymic = *person.PatronymicNormalized
+	}
+	if person.CountryID != nil {
+		task.query.CountryID = *person.CountryID
+	}
+	for _, name := range job.Providers {
+		if e, ok := w.enrichers.Get(name); ok {
+			task.enrichers = append(task.enrichers, e)
+		} else {
+			log.WithField("provider", name).Warn("Skipping inactive enrichment provider")
+		}
+	}
+	task.results = make([]*EnrichmentResult, len(task.enrichers))
+	task.errs = make([]error, len(task.enrichers))
+	return task
+}
+
+// enrich queries every provider concurrently, each with the names of all tasks that need it.
+// Refresh jobs bypass the cache, so they are sent separately from the rest.
+// The provider calls are attributed to the people of the tasks in the audit log.
+func (w *EnrichmentWorker) enrich(ctx context.Context, tasks []*enrichmentTask) {
+	type slot struct {
+		task  *enrichmentTask
+		index int
+	}
+	type group struct {
+		provider string
+		refresh  bool
+	}
+	groups := make(map[group][]slot)
+	for _, task := range tasks {
+		for i, e := range task.enrichers {
+			key := group{provider: e.Name(), refresh: task.job.Refresh}
+			groups[key] = append(groups[key], slot{task: task, index: i})
+		}
+	}
+
+	var wg sync.WaitGroup
+	for key, slots := range groups {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			groupCtx := ctx
+			if key.refresh {
+				groupCtx = WithoutCache(ctx)
+			}
+			queries := make([]EnrichmentQuery, len(slots))
+			people := make(map[string][]int64)
+			for i, s := range slots {
+				queries[i] = s.task.query
+				name := normalizeCacheName(s.task.query.Name)
+				people[name] = append(people[name], s.task.person.ID)
+			}
+			groupCtx = WithCallPeople(groupCtx, people)
+
+			e := slots[0].task.enrichers[slots[0].index]
+			results, errs := enrichBatch(groupCtx, e, queries)
+			for i, s := range slots {
+				s.task.results[s.index], s.task.errs[s.index] = results[i], errs[i]
+				if errs[i] != nil {
+					s.task.log.WithError(errs[i]).WithField("provider", key.provider).Warn("Enrichment failed")
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// finish applies the task's results in provider precedence order and settles the job
+func (w *EnrichmentWorker) finish(ctx context.Context, task *enrichmentTask) {
+	job, person, log := task.job, task.person, task.log
+
+	patch := &domain.Person{Name: person.Name, Surname: person.Surname, Patronymic: person.Patronymic}
+	failed := make(map[string]error)
+	for i, e := range task.enrichers {
+		if task.errs[i] != nil {
+			failed[e.Name()] = task.errs[i]
+			continue
+		}
+		applyEnrichment(patch, task.results[i], e.Name())
+	}
+
+	if len(failed) < len(task.enrichers) {
+		if err := w.people.FillEnrichment(ctx, person.ID, patch, job.Fields, job.Refresh, job.Force); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				w.complete(ctx, job)
+				return
+			}
+			log.WithError(err).Error("Failed to store enrichment")
+			w.retryOrFail(ctx, job, job.Providers, err.Error(), person)
+			return
+		}
+		person.EnrichmentStatus = domain.EnrichmentPartial
+	}
+
+	if len(failed) == 0 {
+		w.setStatus(ctx, person.ID, domain.EnrichmentComplete)
+		w.complete(ctx, job)
+		log.Info("Enrichment completed")
+		return
+	}
+
+	remaining := make([]string, 0, len(failed))
+	reasons := make([]string, 0, len(failed))
+	for name, err := range failed {
+		remaining = append(remaining, name)
+		reasons = append(reasons, name+": "+err.Error())
+	}
+	sort.Strings(remaining)
+	sort.Strings(reasons)
+	w.retryOrFail(ctx, job, remaining, strings.Join(reasons, "; "), person)
+}
+
+// retryOrFail requeues the job for providers with a growing delay, or gives up once the attempts are spent.
+// person may be nil when it could not be loaded.
+func (w *EnrichmentWorker) retryOrFail(ctx context.Context, job *domain.EnrichmentJob, providers []string, reason string, person *domain.Person) {
+	partial := person != nil && person.EnrichmentStatus == domain.EnrichmentPartial
+
+	if job.Attempts >= w.cfg.MaxAttempts {
+		w.log.WithFields(logrus.Fields{
+			"job_id":    job.ID,
+			"person_id": job.PersonID,
+			"reason":    reason,
+		}).Warn("Giving up enrichment job")
+		if err := w.jobs.Fail(ctx, job.ID, reason); err != nil {
+			w.log.WithError(err).Error("Failed to mark enrichment job as failed")
+		}
+		if partial {
+			w.setStatus(ctx, job.PersonID, domain.EnrichmentPartial)
+		} else {
+			w.setStatus(ctx, job.PersonID, domain.EnrichmentFailed)
+		}
+		return
+	}
+
+	if partial {
+		w.setStatus(ctx, job.PersonID, domain.EnrichmentPartial)
+	}
+	runAt := time.Now().UTC().Add(w.retryDelay(job.Attempts))
+	if err := w.jobs.Retry(ctx, job.ID, providers, runAt, reason); err != nil {
+		w.log.WithError(err).Error("Failed to requeue enrichment job")
+	}
+}
+
+func (w *EnrichmentWorker) retryDelay(attempt int) time.Duration {
+	delay := w.cfg.RetryDelay << (attempt - 1)
+	if delay <= 0 || delay > w.cfg.MaxRetryDelay {
+		delay = w.cfg.MaxRetryDelay
+	}
+	return delay
+}
+
+func (w *EnrichmentWorker) complete(ctx context.Context, job *domain.EnrichmentJob) {
+	if err := w.jobs.Complete(ctx, job.ID); err != nil {
+		w.log.WithError(err).Error("Failed to complete enrichment job")
+	}
+}
+
+func (w *EnrichmentWorker) setStatus(ctx context.Context, personID int64, status string) {
+	err := w.people.SetEnrichmentStatus(ctx, personID, status)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		w.log.WithError(err).Errorf("Failed to set enrichment status of person %d", personID)
+	}
+}
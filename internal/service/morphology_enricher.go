@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"person-service/internal/domain"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const ProviderMorphology = "morphology"
+
+// Name parts a GenderRule can look at. They double as the gender source recorded for the person.
+const (
+	RuleFieldPatronymic = "patronymic"
+	RuleFieldSurname    = "surname"
+)
+
+// GenderRule assigns Gender to people whose Field ends in Suffix.
+// Suffixes are matched case-insensitively against the normalized (transliterated) name parts.
+type GenderRule struct {
+	Field       string  `json:"field"`
+	Suffix      string  `json:"suffix"`
+	Gender      string  `json:"gender"`
+	Probability float64 `json:"probability"`
+	// Countries limits the rule to people with one of these country hints; empty applies it to everyone
+	Countries []string `json:"countries,omitempty"`
+}
+
+// slavicCountries are the hints under which endings too common elsewhere still point to an East Slavic surname
+var slavicCountries = []string{"RU", "UA", "BY", "KZ"}
+
+// DefaultGenderRules cover East Slavic patronymics and surnames, in Cyrillic and in ICAO and GOST transliteration.
+// Patronymics decide gender practically always; surname endings are a strong but weaker signal.
+// Latin -in/-ina also end Spanish and English surnames (Medina, Franklin), so they need a Slavic country hint.
+var DefaultGenderRules = []GenderRule{
+	{Field: RuleFieldPatronymic, Suffix: "ich", Gender: "male", Probability: 0.99},
+	{Field: RuleFieldPatronymic, Suffix: "ич", Gender: "male", Probability: 0.99},
+	{Field: RuleFieldPatronymic, Suffix: "vna", Gender: "female", Probability: 0.99},
+	{Field: RuleFieldPatronymic, Suffix: "вна", Gender: "female", Probability: 0.99},
+	{Field: RuleFieldPatronymic, Suffix: "ichna", Gender: "female", Probability: 0.99},
+	{Field: RuleFieldPatronymic, Suffix: "ична", Gender: "female", Probability: 0.99},
+	{Field: RuleFieldPatronymic, Suffix: "ogly", Gender: "male", Probability: 0.99},
+	{Field: RuleFieldPatronymic, Suffix: "оглы", Gender: "male", Probability: 0.99},
+	{Field: RuleFieldPatronymic, Suffix: "kyzy", Gender: "female", Probability: 0.99},
+	{Field: RuleFieldPatronymic, Suffix: "кызы", Gender: "female", Probability: 0.99},
+
+	{Field: RuleFieldSurname, Suffix: "ov", Gender: "male", Probability: 0.95},
+	{Field: RuleFieldSurname, Suffix: "ов", Gender: "male", Probability: 0.95},
+	{Field: RuleFieldSurname, Suffix: "ev", Gender: "male", Probability: 0.95},
+	{Field: RuleFieldSurname, Suffix: "ев", Gender: "male", Probability: 0.95},
+	{Field: RuleFieldSurname, Suffix: "ёв", Gender: "male", Probability: 0.95},
+	{Field: RuleFieldSurname, Suffix: "in", Gender: "male", Probability: 0.85, Countries: slavicCountries},
+	{Field: RuleFieldSurname, Suffix: "ин", Gender: "male", Probability: 0.85},
+	{Field: RuleFieldSurname, Suffix: "ova", Gender: "female", Probability: 0.95},
+	{Field: RuleFieldSurname, Suffix: "ова", Gender: "female", Probability: 0.95},
+	{Field: RuleFieldSurname, Suffix: "eva", Gender: "female", Probability: 0.95},
+	{Field: RuleFieldSurname, Suffix: "ева", Gender: "female", Probability: 0.95},
+	{Field: RuleFieldSurname, Suffix: "ёва", Gender: "female", Probability: 0.95},
+	{Field: RuleFieldSurname, Suffix: "ina", Gender: "female", Probability: 0.85, Countries: slavicCountries},
+	{Field: RuleFieldSurname, Suffix: "ина", Gender: "female", Probability: 0.85},
+	{Field: RuleFieldSurname, Suffix: "skii", Gender: "male", Probability: 0.95},
+	{Field: RuleFieldSurname, Suffix: "skij", Gender: "male", Probability: 0.95},
+	{Field: RuleFieldSurname, Suffix: "skiy", Gender: "male", Probability: 0.95},
+	{Field: RuleFieldSurname, Suffix: "sky", Gender: "male", Probability: 0.95},
+	{Field: RuleFieldSurname, Suffix: "ский", Gender: "male", Probability: 0.95},
+	{Field: RuleFieldSurname, Suffix: "цкий", Gender: "male", Probability: 0.95},
+	{Field: RuleFieldSurname, Suffix: "skaia", Gender: "female", Probability: 0.95},
+	{Field: RuleFieldSurname, Suffix: "skaya", Gender: "female", Probability: 0.95},
+	{Field: RuleFieldSurname, Suffix: "ская", Gender: "female", Probability: 0.95},
+	{Field: RuleFieldSurname, Suffix: "цкая", Gender: "female", Probability: 0.95},
+}
+
+// LoadGenderRules reads a JSON array of GenderRule from path
+func LoadGenderRules(path string) ([]GenderRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gender rules: %w", err)
+	}
+	var rules []GenderRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse gender rules %s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// MorphologyEnricher infers gender from the endings of the patronymic and the surname.
+// Patronymic rules are tried first; within a field the longest matching suffix wins.
+// Whether it overrides or only fills in genderize follows from its place in the provider order.
+type MorphologyEnricher struct {
+	rules map[string][]GenderRule
+	// countryID is the country hint of queries without their own
+	countryID string
+}
+
+// NewMorphologyEnricher validates rules. countryID stands in for the country hint of queries without one.
+func NewMorphologyEnricher(rules []GenderRule, countryID string) (*MorphologyEnricher, error) {
+	byField := make(map[string][]GenderRule)
+	for _, rule := range rules {
+		if rule.Field != RuleFieldPatronymic && rule.Field != RuleFieldSurname {
+			return nil, fmt.Errorf("invalid gender rule %q: unknown field %q", rule.Suffix, rule.Field)
+		}
+		if rule.Gender != "male" && rule.Gender != "female" && rule.Gender != "other" {
+			return nil, fmt.Errorf("invalid gender rule %q: unknown gender %q", rule.Suffix, rule.Gender)
+		}
+		if rule.Suffix == "" || rule.Probability <= 0 || rule.Probability > 1 {
+			return nil, fmt.Errorf("invalid gender rule %q: suffix and a probability in (0, 1] are required", rule.Suffix)
+		}
+		rule.Suffix = strings.ToLower(rule.Suffix)
+		countries := make([]string, len(rule.Countries))
+		for i, country := range rule.Countries {
+			countries[i] = strings.ToUpper(country)
+		}
+		rule.Countries = countries
+		byField[rule.Field] = append(byField[rule.Field], rule)
+	}
+	for _, fieldRules := range byField {
+		sort.SliceStable(fieldRules, func(i, j int) bool {
+			return len([]rune(fieldRules[i].Suffix)) > len([]rune(fieldRules[j].Suffix))
+		})
+	}
+	return &MorphologyEnricher{rules: byField, countryID: strings.ToUpper(countryID)}, nil
+}
+
+func (e *MorphologyEnricher) Name() string {
+	return ProviderMorphology
+}
+
+func (e *MorphologyEnricher) Fields() []string {
+	return []string{domain.FieldGender}
+}
+
+func (e *MorphologyEnricher) Enrich(ctx context.Context, query EnrichmentQuery) (*EnrichmentResult, error) {
+	countryID := strings.ToUpper(query.CountryID)
+	if countryID == "" {
+		countryID = e.countryID
+	}
+	parts := []struct{ field, value string }{
+		{RuleFieldPatronymic, query.Patronymic},
+		{RuleFieldSurname, query.Surname},
+	}
+	for _, part := range parts {
+		value := strings.ToLower(strings.TrimSpace(part.value))
+		if value == "" {
+			continue
+		}
+		for _, rule := range e.rules[part.field] {
+			if len(rule.Countries) > 0 && !slices.Contains(rule.Countries, countryID) {
+				continue
+			}
+			if strings.HasSuffix(value, rule.Suffix) {
+				probability := rule.Probability
+				return &EnrichmentResult{
+					Gender:            rule.Gender,
+					GenderProbability: &probability,
+					GenderSource:      part.field,
+				}, nil
+			}
+		}
+	}
+	return &EnrichmentResult{}, nil
+}
+
+func (e *MorphologyEnricher) EnrichBatch(ctx context.Context, queries []EnrichmentQuery) ([]*EnrichmentResult, []error) {
+	results := make([]*EnrichmentResult, len(queries))
+	errs := make([]error, len(queries))
+	for i, query := range queries {
+		results[i], errs[i] = e.Enrich(ctx, query)
+	}
+	return results, errs
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMorphologyEnricherDefaultRules(t *testing.T) {
+	e, err := NewMorphologyEnricher(DefaultGenderRules, "")
+	if err != nil {
+		t.Fatalf("default rules rejected: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		query       EnrichmentQuery
+		gender      string
+		probability float64
+		source      string
+	}{
+		{name: "male patronymic", query: EnrichmentQuery{Patronymic: "sergeevich"}, gender: "male", probability: 0.99, source: RuleFieldPatronymic},
+		{name: "female patronymic", query: EnrichmentQuery{Patronymic: "Сергеевна"}, gender: "female", probability: 0.99, source: RuleFieldPatronymic},
+		{name: "female Ukrainian patronymic", query: EnrichmentQuery{Patronymic: "illichna"}, gender: "female", probability: 0.99, source: RuleFieldPatronymic},
+		{name: "male surname", query: EnrichmentQuery{Surname: "Ivanov"}, gender: "male", probability: 0.95, source: RuleFieldSurname},
+		{name: "female surname", query: EnrichmentQuery{Surname: "dostoevskaya"}, gender: "female", probability: 0.95, source: RuleFieldSurname},
+		{name: "Cyrillic -ина", query: EnrichmentQuery{Surname: "Пушкина"}, gender: "female", probability: 0.85, source: RuleFieldSurname},
+		{name: "Latin -ina with a Slavic hint", query: EnrichmentQuery{Surname: "pushkina", CountryID: "ru"}, gender: "female", probability: 0.85, source: RuleFieldSurname},
+		{name: "Latin -in with a Slavic hint", query: EnrichmentQuery{Surname: "pushkin", CountryID: "UA"}, gender: "male", probability: 0.85, source: RuleFieldSurname},
+		{name: "Latin -ina without a hint", query: EnrichmentQuery{Surname: "Medina"}},
+		{name: "Latin -ina with another hint", query: EnrichmentQuery{Surname: "molina", CountryID: "ES"}},
+		{name: "Latin -in without a hint", query: EnrichmentQuery{Surname: "Franklin"}},
+		{name: "no rule matches", query: EnrichmentQuery{Surname: "Smith"}},
+		{name: "nothing to look at", query: EnrichmentQuery{Name: "Anna"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := e.Enrich(context.Background(), tt.query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Gender != tt.gender || result.GenderSource != tt.source {
+				t.Fatalf("got %q from %q, want %q from %q", result.Gender, result.GenderSource, tt.gender, tt.source)
+			}
+			if tt.gender == "" {
+				if result.GenderProbability != nil {
+					t.Errorf("probability %v without a gender", *result.GenderProbability)
+				}
+				return
+			}
+			if *result.GenderProbability != tt.probability {
+				t.Errorf("probability = %v, want %v", *result.GenderProbability, tt.probability)
+			}
+		})
+	}
+}
+
+func TestMorphologyEnricherDefaultCountry(t *testing.T) {
+	e, err := NewMorphologyEnricher(DefaultGenderRules, "by")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, _ := e.Enrich(context.Background(), EnrichmentQuery{Surname: "pushkina"})
+	if result.Gender != "female" {
+		t.Errorf("the configured country was not used as hint: %+v", result)
+	}
+	// The query's own hint takes precedence
+	result, _ = e.Enrich(context.Background(), EnrichmentQuery{Surname: "medina", CountryID: "MX"})
+	if result.Gender != "" {
+		t.Errorf("the query's hint was ignored: %+v", result)
+	}
+}
+
+func TestMorphologyEnricherLongestSuffixWins(t *testing.T) {
+	// Listed shortest first, so the order of the rules does not decide
+	e, err := NewMorphologyEnricher([]GenderRule{
+		{Field: RuleFieldSurname, Suffix: "a", Gender: "female", Probability: 0.6},
+		{Field: RuleFieldSurname, Suffix: "ina", Gender: "female", Probability: 0.8},
+		{Field: RuleFieldSurname, Suffix: "kina", Gender: "other", Probability: 0.7, Countries: []string{"ru"}},
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query       EnrichmentQuery
+		gender      string
+		probability float64
+	}{
+		{query: EnrichmentQuery{Surname: "Petrova"}, gender: "female", probability: 0.6},
+		{query: EnrichmentQuery{Surname: "Ilyina"}, gender: "female", probability: 0.8},
+		{query: EnrichmentQuery{Surname: "Pushkina", CountryID: "RU"}, gender: "other", probability: 0.7},
+		// a longer rule for another country falls through to the shorter ones
+		{query: EnrichmentQuery{Surname: "Pushkina"}, gender: "female", probability: 0.8},
+	}
+	for _, tt := range tests {
+		result, _ := e.Enrich(context.Background(), tt.query)
+		if result.Gender != tt.gender || *result.GenderProbability != tt.probability {
+			t.Errorf("%+v: got %q %v, want %q %v", tt.query, result.Gender, *result.GenderProbability, tt.gender, tt.probability)
+		}
+	}
+}
+
+func TestMorphologyEnricherPatronymicFirst(t *testing.T) {
+	e, err := NewMorphologyEnricher(DefaultGenderRules, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The surname says female, the patronymic male; the patronymic decides
+	result, _ := e.Enrich(context.Background(), EnrichmentQuery{Surname: "Ivanova", Patronymic: "Petrovich"})
+	if result.Gender != "male" || result.GenderSource != RuleFieldPatronymic {
+		t.Errorf("got %q from %q, want male from the patronymic", result.Gender, result.GenderSource)
+	}
+	// A patronymic without a matching rule leaves the decision to the surname
+	result, _ = e.Enrich(context.Background(), EnrichmentQuery{Surname: "Ivanova", Patronymic: "Smith"})
+	if result.Gender != "female" || result.GenderSource != RuleFieldSurname {
+		t.Errorf("got %q from %q, want female from the surname", result.Gender, result.GenderSource)
+	}
+}
+
+func TestNewMorphologyEnricherValidation(t *testing.T) {
+	for name, rule := range map[string]GenderRule{
+		"unknown field":    {Field: "name", Suffix: "a", Gender: "female", Probability: 0.5},
+		"unknown gender":   {Field: RuleFieldSurname, Suffix: "a", Gender: "f", Probability: 0.5},
+		"empty suffix":     {Field: RuleFieldSurname, Gender: "female", Probability: 0.5},
+		"zero probability": {Field: RuleFieldSurname, Suffix: "a", Gender: "female"},
+		"probability > 1":  {Field: RuleFieldSurname, Suffix: "a", Gender: "female", Probability: 1.5},
+	} {
+		if _, err := NewMorphologyEnricher([]GenderRule{rule}, ""); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
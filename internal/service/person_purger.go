@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"os"
+	"person-service/internal/config"
+	"person-service/internal/repository"
+	"time"
+)
+
+// PersonPurger removes deleted people for good once they have been deleted for longer than the retention period
+type PersonPurger struct {
+	people repository.PersonRepositoryInterface
+	cfg    config.PurgeConfig
+	log    *logrus.Logger
+}
+
+func NewPersonPurger(people repository.PersonRepositoryInterface, cfg config.PurgeConfig, log *logrus.Logger) *PersonPurger {
+	if log == nil {
+		log = logrus.New()
+		log.SetFormatter(&logrus.JSONFormatter{})
+		log.SetOutput(os.Stdout)
+		log.SetLevel(logrus.DebugLevel)
+	}
+	return &PersonPurger{
+		people: people,
+		cfg:    cfg,
+		log:    log,
+	}
+}
+
+// Run purges right away and then every interval until ctx is done. It returns at once when purging is disabled.
+func (p *PersonPurger) Run(ctx context.Context) {
+	if p.cfg.Retention <= 0 || p.cfg.Interval <= 0 {
+		p.log.Info("Purging of deleted people is disabled")
+		return
+	}
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		if _, err := p.Purge(ctx); err != nil && ctx.Err() == nil {
+			p.log.WithError(err).Error("Failed to purge deleted people")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge removes the people deleted more than the retention period ago and returns their number
+func (p *PersonPurger) Purge(ctx context.Context) (int64, error) {
+	before := time.Now().UTC().Add(-p.cfg.Retention)
+	purged, err := p.people.Purge(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+	if purged > 0 {
+		p.log.WithFields(logrus.Fields{
+			"count":          purged,
+			"deleted_before": before,
+		}).Info("Purged deleted people")
+	}
+	return purged, nil
+}
@@ -7,89 +7,258 @@ import (
 	"github.com/sirupsen/logrus"
 	"os"
 	"person-service/internal/domain"
+	"person-service/internal/names"
 	"person-service/internal/repository"
-	"sync"
+	"strings"
+	"time"
 )
 
 type PersonServiceInterface interface {
-	Create(ctx context.Context, person *domain.Person) (int64, error)
-	GetById(ctx context.Context, id int64) (*domain.Person, error)
-	GetAll(ctx context.Context, filters map[string]interface{}, page, pageSize int) ([]*domain.Person, int, error)
+	// Create stores person and queues the enrichment of the selected fields the caller did not supply
+	Create(ctx context.Context, person *domain.Person, enrich domain.EnrichSelection) (int64, error)
+	// CreateMany stores people in one go and queues their enrichment, see EnrichmentWorker for how it is batched.
+	// enrich holds the selection of each person.
+	CreateMany(ctx context.Context, people []*domain.Person, enrich []domain.EnrichSelection) ([]int64, error)
+	// GetById returns the person, or a *domain.PersonMergedError if it was merged into another one.
+	// Deleted people are only returned with includeDeleted.
+	GetById(ctx context.Context, id int64, includeDeleted bool) (*domain.Person, error)
+	GetAll(ctx context.Context, query domain.PersonQuery) (*domain.PersonPage, error)
+	// Search finds people by their names, tolerating typos and transliteration differences
+	Search(ctx context.Context, search domain.PersonSearch) ([]*domain.PersonSearchResult, error)
 	Update(ctx context.Context, id int64, person *domain.Person) error
+	// Delete marks the person as deleted; it can be restored until it is purged
 	Delete(ctx context.Context, id int64) error
+	// Restore undoes the deletion of the person and returns it
+	Restore(ctx context.Context, id int64) (*domain.Person, error)
 }
 type PersonService struct {
-	repo   repository.PersonRepositoryInterface
-	client *EnrichmentClient
-	log    *logrus.Logger
+	repo      repository.PersonRepositoryInterface
+	jobs      repository.EnrichmentJobRepositoryInterface
+	merges    repository.PersonMergeRepositoryInterface
+	enrichers *EnricherRegistry
+	names     *names.Normalizer
+	log       *logrus.Logger
 }
 
-func NewPersonService(repo repository.PersonRepositoryInterface, log *logrus.Logger) PersonServiceInterface {
+// NewPersonService creates the service. New people are enriched asynchronously through jobs.
+// Names are cleaned up and given normalized keys with normalizer before they are stored.
+// Lookups of merged people are redirected through merges.
+func NewPersonService(
+	repo repository.PersonRepositoryInterface,
+	jobs repository.EnrichmentJobRepositoryInterface,
+	merges repository.PersonMergeRepositoryInterface,
+	enrichers *EnricherRegistry,
+	normalizer *names.Normalizer,
+	log *logrus.Logger,
+) PersonServiceInterface {
 	if log == nil {
 		log = logrus.New()
 		log.SetFormatter(&logrus.JSONFormatter{})
 		log.SetOutput(os.Stdout)
 		log.SetLevel(logrus.DebugLevel)
 	}
+	if enrichers == nil {
+		enrichers = NewEnricherRegistry()
+	}
+	if normalizer == nil {
+		normalizer = &names.Normalizer{}
+	}
 	return &PersonService{
-		repo:   repo,
-		client: NewEnrichmentClient(log),
-		log:    log,
+		repo:      repo,
+		jobs:      jobs,
+		merges:    merges,
+		enrichers: enrichers,
+		names:     normalizer,
+		log:       log,
 	}
 }
 
-func (s *PersonService) Create(ctx context.Context, person *domain.Person) (int64, error) {
-	if person.Name == "" || person.Surname == "" {
-		s.log.Error("Name and surname are required")
-		return 0, fmt.Errorf("name and surname are required")
+// normalize cleans up the names of person and fills in their normalized keys
+func (s *PersonService) normalize(person *domain.Person) {
+	person.Name = names.Display(person.Name)
+	person.Surname = names.Display(person.Surname)
+	person.NameNormalized = s.names.Key(person.Name)
+	person.SurnameNormalized = s.names.Key(person.Surname)
+	person.PatronymicNormalized = nil
+	if person.Patronymic != nil {
+		patronymic := names.Display(*person.Patronymic)
+		if patronymic == "" {
+			person.Patronymic = nil
+			return
+		}
+		key := s.names.Key(patronymic)
+		person.Patronymic = &patronymic
+		person.PatronymicNormalized = &key
 	}
-	s.log.Debugf("Creating person with name %s and surname %s", person.Name, person.Surname)
-
-	var wg sync.WaitGroup
-	wg.Add(3)
+}
 
-	go func() {
-		defer wg.Done()
-		if age, err := s.client.GetAge(ctx, person.Name); err == nil {
-			person.Age = age
-		} else {
-			s.log.WithError(err).Warn("Age enrichment failed")
+// searchFilter adds the normalized keys of the name substring filters, so that "dmitriy" also finds "Дмитрий"
+func searchFilter(normalizer *names.Normalizer, filter domain.PersonFilter) domain.PersonFilter {
+	for _, f := range []**domain.StringFilter{&filter.Name, &filter.Surname, &filter.Patronymic} {
+		if *f == nil || (*f).Contains == nil {
+			continue
 		}
-	}()
+		search := **f
+		value := names.Clean(*search.Contains)
+		key := normalizer.Key(value)
+		search.Contains, search.Normalized = &value, &key
+		*f = &search
+	}
+	return filter
+}
 
-	go func() {
-		defer wg.Done()
-		if gender, err := s.client.GetGender(ctx, person.Name); err == nil {
-			person.Gender = gender
-		} else {
-			s.log.WithError(err).Warn("Gender enrichment failed")
+// enrichmentPlan marks the fields the caller supplied for person as set manually and returns
+// the selected fields that are left to enrich together with their providers.
+// It sets the initial enrichment status accordingly.
+func (s *PersonService) enrichmentPlan(person *domain.Person, enrich domain.EnrichSelection) ([]string, []string) {
+	supplied := map[string]bool{
+		domain.FieldAge:         person.Age != 0,
+		domain.FieldGender:      person.Gender != "",
+		domain.FieldNationality: person.Nationality != "",
+	}
+	now := time.Now().UTC()
+	for _, field := range domain.EnrichableFields {
+		if !supplied[field] {
+			continue
 		}
-	}()
+		if person.Provenance == nil {
+			person.Provenance = make(map[string]*domain.FieldProvenance)
+		}
+		person.Provenance[field] = &domain.FieldProvenance{Source: domain.ProvenanceManual, FetchedAt: now}
+	}
 
-	go func() {
-		defer wg.Done()
-		if nationality, err := s.client.GetNationality(ctx, person.Name); err == nil {
-			person.Nationality = nationality
-		} else {
-			s.log.WithError(err).Warn("Nationality enrichment failed")
+	var fields []string
+	for _, field := range enrich.Fields() {
+		if !supplied[field] {
+			fields = append(fields, field)
 		}
-	}()
+	}
+	var providers []string
+	if len(fields) > 0 {
+		providers = s.enrichers.ProvidersFor(fields)
+	}
 
-	wg.Wait()
+	person.EnrichmentStatus = domain.EnrichmentPending
+	if len(providers) == 0 {
+		person.EnrichmentStatus = domain.EnrichmentComplete
+	}
+	return fields, providers
+}
+
+func (s *PersonService) Create(ctx context.Context, person *domain.Person, enrich domain.EnrichSelection) (int64, error) {
+	s.normalize(person)
+	if person.Name == "" || person.Surname == "" {
+		s.log.Error("Name and surname are required")
+		return 0, fmt.Errorf("name and surname are required")
+	}
+	s.log.Debugf("Creating person with name %s and surname %s", person.Name, person.Surname)
+
+	fields, providers := s.enrichmentPlan(person, enrich)
 
 	id, err := s.repo.Create(ctx, person)
 	if err != nil {
 		s.log.Errorf("Failed to create person with name %s: %v", person.Name, err)
 		return 0, fmt.Errorf("failed to create person: %w", err)
 	}
+	if len(providers) == 0 {
+		return id, nil
+	}
+
+	job := &domain.EnrichmentJob{
+		PersonID:  id,
+		Providers: providers,
+		Fields:    fields,
+	}
+	if err := s.jobs.Enqueue(ctx, job); err != nil {
+		// The person is stored; it just won't be enriched until someone asks again
+		s.log.WithError(err).Errorf("Failed to enqueue enrichment of person %d", id)
+		person.EnrichmentStatus = domain.EnrichmentFailed
+		if err := s.repo.SetEnrichmentStatus(ctx, id, domain.EnrichmentFailed); err != nil {
+			s.log.WithError(err).Errorf("Failed to mark enrichment of person %d as failed", id)
+		}
+	}
 	return id, nil
 }
 
-func (s *PersonService) GetById(ctx context.Context, id int64) (*domain.Person, error) {
+func (s *PersonService) CreateMany(ctx context.Context, people []*domain.Person, enrich []domain.EnrichSelection) ([]int64, error) {
+	for i, person := range people {
+		s.normalize(person)
+		if person.Name == "" || person.Surname == "" {
+			s.log.Errorf("Name and surname are required (person %d)", i)
+			return nil, fmt.Errorf("name and surname are required")
+		}
+	}
+	s.log.Debugf("Creating %d people", len(people))
+
+	// People that need the same fields share one EnqueueMany call
+	type plan struct {
+		fields    []string
+		providers []string
+		people    []*domain.Person
+	}
+	var plans []*plan
+	plansByFields := make(map[string]*plan)
+	for i, person := range people {
+		var selection domain.EnrichSelection
+		if i < len(enrich) {
+			selection = enrich[i]
+		}
+		fields, providers := s.enrichmentPlan(person, selection)
+		if len(providers) == 0 {
+			continue
+		}
+		key := strings.Join(fields, ",")
+		p, ok := plansByFields[key]
+		if !ok {
+			p = &plan{fields: fields, providers: providers}
+			plansByFields[key] = p
+			plans = append(plans, p)
+		}
+		p.people = append(p.people, person)
+	}
+
+	ids, err := s.repo.CreateMany(ctx, people)
+	if err != nil {
+		s.log.Errorf("Failed to create %d people: %v", len(people), err)
+		return nil, fmt.Errorf("failed to create people: %w", err)
+	}
+	for i, person := range people {
+		person.ID = ids[i]
+	}
+
+	for _, p := range plans {
+		planIDs := make([]int64, len(p.people))
+		for i, person := range p.people {
+			planIDs[i] = person.ID
+		}
+		if err := s.jobs.EnqueueMany(ctx, planIDs, p.providers, p.fields); err != nil {
+			s.log.WithError(err).Errorf("Failed to enqueue enrichment of %d people", len(planIDs))
+			for _, person := range p.people {
+				person.EnrichmentStatus = domain.EnrichmentFailed
+				if err := s.repo.SetEnrichmentStatus(ctx, person.ID, domain.EnrichmentFailed); err != nil {
+					s.log.WithError(err).Errorf("Failed to mark enrichment of person %d as failed", person.ID)
+				}
+			}
+		}
+	}
+	return ids, nil
+}
+
+func (s *PersonService) GetById(ctx context.Context, id int64, includeDeleted bool) (*domain.Person, error) {
 	s.log.Debugf("Getting person by ID: %d", id)
-	person, err := s.repo.GetById(ctx, id)
+	person, err := s.repo.GetById(ctx, id, includeDeleted)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
+			if s.merges != nil {
+				survivorID, mergeErr := s.merges.GetSurvivorID(ctx, id)
+				if mergeErr == nil {
+					s.log.Debugf("Person with ID %d was merged into %d", id, survivorID)
+					return nil, &domain.PersonMergedError{ID: id, SurvivorID: survivorID}
+				}
+				if !errors.Is(mergeErr, repository.ErrNotFound) {
+					return nil, fmt.Errorf("failed to get person: %w", mergeErr)
+				}
+			}
 			s.log.Warnf("Person with ID %d not found", id)
 			return nil, err
 		}
@@ -99,35 +268,66 @@ func (s *PersonService) GetById(ctx context.Context, id int64) (*domain.Person,
 	return person, nil
 }
 
-func (s *PersonService) GetAll(ctx context.Context, filters map[string]interface{}, page, pageSize int) ([]*domain.Person, int, error) {
-	if page < 1 {
-		page = 1
+func (s *PersonService) GetAll(ctx context.Context, query domain.PersonQuery) (*domain.PersonPage, error) {
+	if query.Limit < 1 {
+		query.Limit = 10
 	}
-	if pageSize < 1 {
-		pageSize = 10
+	if query.Offset < 0 {
+		query.Offset = 0
 	}
-	limit := pageSize
-	offset := (page - 1) * pageSize
 
+	query.Filter = searchFilter(s.names, query.Filter)
 	s.log.WithFields(logrus.Fields{
-		"filters":   filters,
-		"page":      page,
-		"page_size": pageSize,
+		"filters": query.Filter,
+		"sort":    query.Sort,
+		"limit":   query.Limit,
+		"offset":  query.Offset,
+		"cursor":  query.Cursor,
 	}).Debug("Getting people")
-	people, total, err := s.repo.GetAll(ctx, filters, limit, offset)
+	page, err := s.repo.GetAll(ctx, query)
 	if err != nil {
+		if errors.Is(err, domain.ErrInvalidCursor) {
+			return nil, err
+		}
 		s.log.WithError(err).Error("Failed to get people")
-		return nil, 0, fmt.Errorf("failed to get people: %w", err)
+		return nil, fmt.Errorf("failed to get people: %w", err)
 	}
 	s.log.WithFields(logrus.Fields{
-		"count": len(people),
-		"total": total,
+		"count": len(page.People),
+		"total": page.Total,
 	}).Debug("Retrieved people")
-	return people, total, nil
+	return page, nil
+}
+
+func (s *PersonService) Search(ctx context.Context, search domain.PersonSearch) ([]*domain.PersonSearchResult, error) {
+	search.Query = names.Clean(search.Query)
+	search.Key = s.names.Key(search.Query)
+	if search.MinSimilarity <= 0 {
+		search.MinSimilarity = domain.DefaultSearchSimilarity
+	}
+	if search.Limit < 1 {
+		search.Limit = 10
+	}
+	search.Filter = searchFilter(s.names, search.Filter)
+
+	s.log.WithFields(logrus.Fields{
+		"query":          search.Query,
+		"key":            search.Key,
+		"min_similarity": search.MinSimilarity,
+		"filters":        search.Filter,
+		"limit":          search.Limit,
+	}).Debug("Searching people")
+	results, err := s.repo.Search(ctx, search)
+	if err != nil {
+		s.log.WithError(err).Error("Failed to search people")
+		return nil, fmt.Errorf("failed to search people: %w", err)
+	}
+	return results, nil
 }
 
 func (s *PersonService) Update(ctx context.Context, id int64, person *domain.Person) error {
 	s.log.Debugf("Updating person ID: %d", id)
+	s.normalize(person)
 
 	err := s.repo.Update(ctx, id, person)
 	if err != nil {
@@ -154,3 +354,24 @@ func (s *PersonService) Delete(ctx context.Context, id int64) error {
 	}
 	return nil
 }
+
+func (s *PersonService) Restore(ctx context.Context, id int64) (*domain.Person, error) {
+	s.log.Debugf("Restoring person ID: %d", id)
+	if err := s.repo.Restore(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			s.log.Warnf("Person with ID %d not found", id)
+			return nil, err
+		}
+		if errors.Is(err, repository.ErrNotDeleted) {
+			s.log.Debugf("Person with ID %d is not deleted", id)
+			return nil, err
+		}
+		s.log.Errorf("Failed to restore person ID %d: %v", id, err)
+		return nil, fmt.Errorf("failed to restore person: %w", err)
+	}
+	person, err := s.repo.GetById(ctx, id, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get restored person: %w", err)
+	}
+	return person, nil
+}
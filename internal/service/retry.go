@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"person-service/internal/config"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var ErrRateLimited = errors.New("enrichment provider rate limit exhausted")
+
+// statusError is a final non-200 answer of a provider
+type statusError struct {
+	provider string
+	status   int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s returned status: %d", e.provider, e.status)
+}
+
+// rateLimiter remembers until when a provider told us its quota is used up
+type rateLimiter struct {
+	mu           sync.Mutex
+	blockedUntil time.Time
+}
+
+// observe reads the X-Rate-Limit-Remaining/X-Rate-Limit-Reset headers of a response
+func (l *rateLimiter) observe(header http.Header) {
+	remaining, err := strconv.Atoi(header.Get("X-Rate-Limit-Remaining"))
+	if err != nil || remaining > 0 {
+		return
+	}
+	reset, err := strconv.Atoi(header.Get("X-Rate-Limit-Reset"))
+	if err != nil || reset <= 0 {
+		return
+	}
+	l.block(time.Duration(reset) * time.Second)
+}
+
+func (l *rateLimiter) block(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if until := time.Now().Add(d); until.After(l.blockedUntil) {
+		l.blockedUntil = until
+	}
+}
+
+func (l *rateLimiter) wait() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return time.Until(l.blockedUntil)
+}
+
+// backoff returns a fully jittered exponential delay for the given attempt, starting at 1
+func backoff(policy config.RetryConfig, attempt int) time.Duration {
+	ceiling := policy.BaseDelay << (attempt - 1)
+	if ceiling <= 0 || ceiling > policy.MaxDelay {
+		ceiling = policy.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling)
+}
+
+// retryAfter parses a Retry-After header given either in seconds or as an HTTP date
+func retryAfter(header http.Header) (time.Duration, bool) {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date), true
+	}
+	return 0, false
+}
+
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// getWithRetry calls the provider until it answers 200, the error is final or the retry budget is spent.
+// The caller owns the body of the returned response.
+func (c *EnrichmentClient) getWithRetry(ctx context.Context, provider string, params url.Values) (*http.Response, error) {
+	p := c.providers[provider]
+	policy := p.cfg.Retry
+
+	for attempt := 1; ; attempt++ {
+		if wait := p.limiter.wait(); wait > 0 {
+			if wait > policy.MaxDelay {
+				return nil, fmt.Errorf("%s: %w, resets in %s", provider, ErrRateLimited, wait.Round(time.Second))
+			}
+			if err := sleep(ctx, wait); err != nil {
+				return nil, fmt.Errorf("%s request failed: %w", provider, err)
+			}
+		}
+
+		resp, err := c.get(ctx, provider, params)
+		var delay time.Duration
+		switch {
+		case err != nil:
+			if ctx.Err() != nil || attempt >= policy.MaxAttempts {
+				return nil, fmt.Errorf("%s request failed: %w", provider, err)
+			}
+			delay = backoff(policy, attempt)
+		case resp.StatusCode == http.StatusOK:
+			p.limiter.observe(resp.Header)
+			return resp, nil
+		default:
+			p.limiter.observe(resp.Header)
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+
+			if !retryableStatus(resp.StatusCode) || attempt >= policy.MaxAttempts {
+				return nil, &statusError{provider: provider, status: resp.StatusCode}
+			}
+			delay = backoff(policy, attempt)
+			if after, ok := retryAfter(resp.Header); ok {
+				if after > policy.MaxDelay {
+					p.limiter.block(after)
+					return nil, fmt.Errorf("%s: %w, retry after %s", provider, ErrRateLimited, after.Round(time.Second))
+				}
+				delay = after
+			}
+		}
+
+		c.log.WithFields(logrus.Fields{
+			"provider": provider,
+			"attempt":  attempt,
+			"delay":    delay.String(),
+		}).Debug("Retrying enrichment request")
+		if err := sleep(ctx, delay); err != nil {
+			return nil, fmt.Errorf("%s request failed: %w", provider, err)
+		}
+	}
+}
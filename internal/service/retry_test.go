@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"person-service/internal/config"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testLogger() *logrus.Logger {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	return log
+}
+
+func TestBackoff(t *testing.T) {
+	policy := config.RetryConfig{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	tests := []struct {
+		attempt int
+		ceiling time.Duration
+	}{
+		{attempt: 1, ceiling: 100 * time.Millisecond},
+		{attempt: 2, ceiling: 200 * time.Millisecond},
+		{attempt: 4, ceiling: 800 * time.Millisecond},
+		{attempt: 5, ceiling: time.Second},
+		{attempt: 100, ceiling: time.Second}, // the shift overflows
+	}
+
+	for _, tt := range tests {
+		var longest time.Duration
+		for range 1000 {
+			d := backoff(policy, tt.attempt)
+			if d < 0 || d >= tt.ceiling {
+				t.Fatalf("attempt %d: delay %v out of [0, %v)", tt.attempt, d, tt.ceiling)
+			}
+			longest = max(longest, d)
+		}
+		// Full jitter spreads the delays over the whole range
+		if longest < tt.ceiling/2 {
+			t.Errorf("attempt %d: largest of 1000 delays is %v, expected jitter up to %v", tt.attempt, longest, tt.ceiling)
+		}
+	}
+
+	if d := backoff(config.RetryConfig{}, 1); d != 0 {
+		t.Errorf("delay without a budget = %v, want 0", d)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		min   time.Duration
+		max   time.Duration
+		ok    bool
+	}{
+		{name: "missing", value: ""},
+		{name: "seconds", value: "3", min: 3 * time.Second, max: 3 * time.Second, ok: true},
+		{name: "zero seconds", value: "0", ok: true},
+		{
+			name:  "HTTP date",
+			value: time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat),
+			// the date has second precision
+			min: 8 * time.Second,
+			max: 10 * time.Second,
+			ok:  true,
+		},
+		{name: "garbage", value: "soon"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.value != "" {
+				header.Set("Retry-After", tt.value)
+			}
+			got, ok := retryAfter(header)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if got < tt.min || got > tt.max {
+				t.Errorf("got %v, want between %v and %v", got, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestRateLimiterObserve(t *testing.T) {
+	tests := []struct {
+		name      string
+		remaining string
+		reset     string
+		blocked   bool
+	}{
+		{name: "quota left", remaining: "5", reset: "60"},
+		{name: "quota used up", remaining: "0", reset: "60", blocked: true},
+		{name: "no headers"},
+		{name: "no reset", remaining: "0"},
+		{name: "bad reset", remaining: "0", reset: "later"},
+		{name: "bad remaining", remaining: "none", reset: "60"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.remaining != "" {
+				header.Set("X-Rate-Limit-Remaining", tt.remaining)
+			}
+			if tt.reset != "" {
+				header.Set("X-Rate-Limit-Reset", tt.reset)
+			}
+			var limiter rateLimiter
+			limiter.observe(header)
+
+			wait := limiter.wait()
+			if tt.blocked && (wait <= 59*time.Second || wait > time.Minute) {
+				t.Errorf("wait = %v, want about a minute", wait)
+			}
+			if !tt.blocked && wait > 0 {
+				t.Errorf("wait = %v, want none", wait)
+			}
+		})
+	}
+}
+
+func TestRateLimiterKeepsLongerBlock(t *testing.T) {
+	var limiter rateLimiter
+	limiter.block(time.Minute)
+	limiter.block(time.Second)
+	if wait := limiter.wait(); wait <= time.Second {
+		t.Errorf("wait = %v, a shorter block replaced the longer one", wait)
+	}
+}
+
+func TestRetryableStatus(t *testing.T) {
+	for status, want := range map[int]bool{
+		http.StatusTooManyRequests:     true,
+		http.StatusInternalServerError: true,
+		http.StatusBadGateway:          true,
+		http.StatusServiceUnavailable:  true,
+		http.StatusGatewayTimeout:      true,
+		http.StatusBadRequest:          false,
+		http.StatusUnauthorized:        false,
+		http.StatusNotFound:            false,
+		http.StatusUnprocessableEntity: false,
+	} {
+		if got := retryableStatus(status); got != want {
+			t.Errorf("retryableStatus(%d) = %v, want %v", status, got, want)
+		}
+	}
+}
+
+// retryServer answers with the responses of script in turn, repeating the last one
+func retryServer(t *testing.T, script ...func(w http.ResponseWriter)) (*EnrichmentClient, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+		script[min(n, len(script))-1](w)
+	}))
+	t.Cleanup(server.Close)
+
+	provider := config.ProviderConfig{
+		BaseURL: server.URL,
+		Timeout: time.Second,
+		Retry:   config.RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 50 * time.Millisecond},
+	}
+	return NewEnrichmentClient(&config.Config{Agify: provider}, nil, nil, testLogger()), &calls
+}
+
+func respondWith(code int, headers ...string) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		for i := 0; i+1 < len(headers); i += 2 {
+			w.Header().Set(headers[i], headers[i+1])
+		}
+		w.WriteHeader(code)
+		_, _ = w.Write([]byte(`{"name":"ivan","age":47}`))
+	}
+}
+
+func TestGetWithRetry(t *testing.T) {
+	tests := []struct {
+		name   string
+		script []func(w http.ResponseWriter)
+		calls  int32
+		status int
+		err    error
+	}{
+		{
+			name:   "success",
+			script: []func(w http.ResponseWriter){respondWith(http.StatusOK)},
+			calls:  1,
+		},
+		{
+			name:   "retryable statuses until success",
+			script: []func(w http.ResponseWriter){respondWith(http.StatusServiceUnavailable), respondWith(http.StatusTooManyRequests), respondWith(http.StatusOK)},
+			calls:  3,
+		},
+		{
+			name:   "final status",
+			script: []func(w http.ResponseWriter){respondWith(http.StatusNotFound)},
+			calls:  1,
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "attempts spent",
+			script: []func(w http.ResponseWriter){respondWith(http.StatusBadGateway)},
+			calls:  3,
+			status: http.StatusBadGateway,
+		},
+		{
+			name:   "short Retry-After is waited for",
+			script: []func(w http.ResponseWriter){respondWith(http.StatusTooManyRequests, "Retry-After", "0"), respondWith(http.StatusOK)},
+			calls:  2,
+		},
+		{
+			name:   "long Retry-After is not waited for",
+			script: []func(w http.ResponseWriter){respondWith(http.StatusTooManyRequests, "Retry-After", "120")},
+			calls:  1,
+			err:    ErrRateLimited,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, calls := retryServer(t, tt.script...)
+			resp, err := client.getWithRetry(context.Background(), ProviderAgify, url.Values{"name": {"ivan"}})
+			if resp != nil {
+				resp.Body.Close()
+			}
+
+			switch {
+			case tt.status != 0:
+				var statusErr *statusError
+				if !errors.As(err, &statusErr) || statusErr.status != tt.status {
+					t.Errorf("error = %v, want status %d", err, tt.status)
+				}
+			case tt.err != nil:
+				if !errors.Is(err, tt.err) {
+					t.Errorf("error = %v, want %v", err, tt.err)
+				}
+			case err != nil:
+				t.Errorf("unexpected error: %v", err)
+			}
+			if got := calls.Load(); got != tt.calls {
+				t.Errorf("provider called %d times, want %d", got, tt.calls)
+			}
+		})
+	}
+}
+
+func TestGetWithRetryHonoursRateLimit(t *testing.T) {
+	client, calls := retryServer(t, respondWith(http.StatusOK, "X-Rate-Limit-Remaining", "0", "X-Rate-Limit-Reset", "60"))
+	params := url.Values{"name": {"ivan"}}
+
+	resp, err := client.getWithRetry(context.Background(), ProviderAgify, params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	// The quota is used up for longer than MaxDelay, so the next call fails without a request
+	if _, err := client.getWithRetry(context.Background(), ProviderAgify, params); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("error = %v, want %v", err, ErrRateLimited)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("provider called %d times, want 1", got)
+	}
+}
+
+func TestGetWithRetryStopsOnCancel(t *testing.T) {
+	client, calls := retryServer(t, respondWith(http.StatusServiceUnavailable))
+	client.providers[ProviderAgify].cfg.Retry = config.RetryConfig{MaxAttempts: 10, BaseDelay: time.Second, MaxDelay: time.Second}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	if _, err := client.getWithRetry(ctx, ProviderAgify, url.Values{"name": {"ivan"}}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(started); elapsed > 900*time.Millisecond {
+		t.Errorf("returned after %v, the backoff outlived the context", elapsed)
+	}
+	if got := calls.Load(); got >= 10 {
+		t.Errorf("provider called %d times", got)
+	}
+}
@@ -0,0 +1 @@
+DROP TABLE enrichment_cache;
\ No newline at end of file
@@ -0,0 +1,8 @@
+CREATE TABLE enrichment_cache (
+    name VARCHAR(100) NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    fetched_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ttl INTERVAL NOT NULL,
+    PRIMARY KEY (provider, name)
+);
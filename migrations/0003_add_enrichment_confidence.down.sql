@@ -0,0 +1,6 @@
+DROP TABLE person_nationalities;
+
+ALTER TABLE people
+    DROP COLUMN gender_probability,
+    DROP COLUMN gender_count,
+    DROP COLUMN nationality_probability;
@@ -0,0 +1,15 @@
+ALTER TABLE people
+    ADD COLUMN gender_probability DOUBLE PRECISION CHECK (gender_probability >= 0 AND gender_probability <= 1),
+    ADD COLUMN gender_count INTEGER CHECK (gender_count >= 0),
+    ADD COLUMN nationality_probability DOUBLE PRECISION CHECK (nationality_probability >= 0 AND nationality_probability <= 1);
+
+CREATE INDEX idx_people_gender_probability ON people (gender_probability);
+CREATE INDEX idx_people_nationality_probability ON people (nationality_probability);
+
+CREATE TABLE person_nationalities (
+    person_id INTEGER NOT NULL REFERENCES people (id) ON DELETE CASCADE,
+    rank SMALLINT NOT NULL CHECK (rank >= 1),
+    country_id VARCHAR(2) NOT NULL,
+    probability DOUBLE PRECISION NOT NULL CHECK (probability >= 0 AND probability <= 1),
+    PRIMARY KEY (person_id, rank)
+);
@@ -0,0 +1,12 @@
+CREATE TABLE enrichment_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    person_id INTEGER NOT NULL REFERENCES people (id) ON DELETE CASCADE,
+    providers TEXT[] NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    run_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_enrichment_jobs_run_at ON enrichment_jobs (run_at, id);
+CREATE INDEX idx_enrichment_jobs_person_id ON enrichment_jobs (person_id);
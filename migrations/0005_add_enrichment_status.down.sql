@@ -0,0 +1,12 @@
+-- Before this migration every row of enrichment_jobs was still to be run
+DELETE FROM enrichment_jobs WHERE status IN ('done', 'failed');
+
+DROP INDEX idx_enrichment_jobs_queue;
+CREATE INDEX idx_enrichment_jobs_run_at ON enrichment_jobs (run_at, id);
+
+ALTER TABLE enrichment_jobs
+    DROP COLUMN status,
+    DROP COLUMN locked_at,
+    DROP COLUMN updated_at;
+
+ALTER TABLE people DROP COLUMN enrichment_status;
@@ -0,0 +1,18 @@
+ALTER TABLE people
+    ADD COLUMN enrichment_status VARCHAR(20) NOT NULL DEFAULT 'complete'
+        CHECK (enrichment_status IN ('pending', 'partial', 'complete', 'failed'));
+ALTER TABLE people ALTER COLUMN enrichment_status SET DEFAULT 'pending';
+
+CREATE INDEX idx_people_enrichment_status ON people (enrichment_status);
+
+ALTER TABLE enrichment_jobs
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'running', 'done', 'failed')),
+    ADD COLUMN locked_at TIMESTAMP,
+    ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+DROP INDEX idx_enrichment_jobs_run_at;
+CREATE INDEX idx_enrichment_jobs_queue ON enrichment_jobs (run_at, id) WHERE status IN ('queued', 'running');
+
+-- People still waiting for deferred providers were enriched only in part
+UPDATE people SET enrichment_status = 'partial'
+WHERE id IN (SELECT person_id FROM enrichment_jobs);
@@ -0,0 +1,6 @@
+ALTER TABLE enrichment_jobs
+    DROP COLUMN fields,
+    DROP COLUMN refresh,
+    DROP COLUMN batch_id;
+
+DROP TABLE enrichment_batches;
@@ -0,0 +1,16 @@
+CREATE TABLE enrichment_batches (
+    id BIGSERIAL PRIMARY KEY,
+    filters JSONB NOT NULL DEFAULT '{}',
+    fields TEXT[] NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'enqueuing' CHECK (status IN ('enqueuing', 'running', 'failed')),
+    total INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE enrichment_jobs
+    ADD COLUMN fields TEXT[] NOT NULL DEFAULT '{age,gender,nationality}',
+    ADD COLUMN refresh BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN batch_id BIGINT REFERENCES enrichment_batches (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_enrichment_jobs_batch_id ON enrichment_jobs (batch_id);
@@ -0,0 +1,4 @@
+ALTER TABLE people
+    DROP COLUMN country_id,
+    DROP COLUMN age_localization,
+    DROP COLUMN gender_localization;
@@ -0,0 +1,4 @@
+ALTER TABLE people
+    ADD COLUMN country_id VARCHAR(2),
+    ADD COLUMN age_localization VARCHAR(2),
+    ADD COLUMN gender_localization VARCHAR(2);
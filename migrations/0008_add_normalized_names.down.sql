@@ -0,0 +1,4 @@
+ALTER TABLE people
+    DROP COLUMN name_normalized,
+    DROP COLUMN surname_normalized,
+    DROP COLUMN patronymic_normalized;
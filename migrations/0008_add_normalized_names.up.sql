@@ -0,0 +1,14 @@
+ALTER TABLE people
+    ADD COLUMN name_normalized VARCHAR(200),
+    ADD COLUMN surname_normalized VARCHAR(400),
+    ADD COLUMN patronymic_normalized VARCHAR(400);
+
+-- Existing rows get a lower-cased key; they are transliterated the next time they are updated
+UPDATE people
+SET name_normalized = lower(btrim(name)),
+    surname_normalized = lower(btrim(surname)),
+    patronymic_normalized = lower(btrim(patronymic));
+
+ALTER TABLE people
+    ALTER COLUMN name_normalized SET NOT NULL,
+    ALTER COLUMN surname_normalized SET NOT NULL;
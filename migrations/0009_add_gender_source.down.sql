@@ -0,0 +1 @@
+ALTER TABLE people DROP COLUMN gender_source;
@@ -0,0 +1,3 @@
+ALTER TABLE people ADD COLUMN gender_source VARCHAR(50);
+
+UPDATE people SET gender_source = 'genderize' WHERE gender_probability IS NOT NULL;
@@ -0,0 +1,4 @@
+ALTER TABLE enrichment_batches DROP COLUMN force;
+ALTER TABLE enrichment_jobs DROP COLUMN force;
+
+DROP TABLE person_field_provenance;
@@ -0,0 +1,22 @@
+CREATE TABLE person_field_provenance (
+    person_id INTEGER NOT NULL REFERENCES people (id) ON DELETE CASCADE,
+    field VARCHAR(20) NOT NULL CHECK (field IN ('age', 'gender', 'nationality')),
+    -- provider name, or 'manual' for values set through the API, which re-enrichment leaves alone unless forced
+    source VARCHAR(50) NOT NULL,
+    confidence DOUBLE PRECISION CHECK (confidence >= 0 AND confidence <= 1),
+    fetched_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (person_id, field)
+);
+
+CREATE INDEX idx_person_field_provenance_manual ON person_field_provenance (person_id) WHERE source = 'manual';
+
+INSERT INTO person_field_provenance (person_id, field, source, confidence, fetched_at)
+SELECT id, 'gender', gender_source, gender_probability, created_at
+FROM people WHERE gender_source IS NOT NULL AND COALESCE(gender, '') <> '';
+
+INSERT INTO person_field_provenance (person_id, field, source, confidence, fetched_at)
+SELECT id, 'nationality', 'nationalize', nationality_probability, created_at
+FROM people WHERE nationality_probability IS NOT NULL AND COALESCE(nationality, '') <> '';
+
+ALTER TABLE enrichment_jobs ADD COLUMN force BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE enrichment_batches ADD COLUMN force BOOLEAN NOT NULL DEFAULT false;
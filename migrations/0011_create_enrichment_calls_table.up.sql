@@ -0,0 +1,16 @@
+CREATE TABLE enrichment_calls (
+    id BIGSERIAL PRIMARY KEY,
+    provider VARCHAR(50) NOT NULL,
+    -- a batched call asks for up to 10 names and may serve several people
+    names TEXT[] NOT NULL,
+    country_id VARCHAR(2),
+    person_ids INTEGER[] NOT NULL DEFAULT '{}',
+    status_code INTEGER,
+    latency_ms INTEGER NOT NULL,
+    response JSONB,
+    error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_enrichment_calls_person_ids ON enrichment_calls USING GIN (person_ids);
+CREATE INDEX idx_enrichment_calls_created_at ON enrichment_calls (created_at);
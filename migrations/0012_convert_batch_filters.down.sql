@@ -0,0 +1,14 @@
+-- Only the filters the old query-parameter map could express survive the way back
+UPDATE enrichment_batches
+SET filters = jsonb_strip_nulls(jsonb_build_object(
+    'name', filters #> '{name,contains}',
+    'name_normalized', filters #> '{name,normalized}',
+    'surname', filters #> '{surname,contains}',
+    'surname_normalized', filters #> '{surname,normalized}',
+    'age', filters #> '{age,eq}',
+    'gender', filters #> '{gender,eq}',
+    'nationality', filters #> '{nationality,contains}',
+    'enrichment_status', filters #> '{enrichment_status,eq}',
+    'gender_probability_gte', filters #> '{gender_probability,min}',
+    'nationality_probability_gte', filters #> '{nationality_probability,min}'
+));
@@ -0,0 +1,12 @@
+-- enrichment_batches.filters moves from the flat query-parameter map to the typed PersonFilter layout
+UPDATE enrichment_batches
+SET filters = jsonb_strip_nulls(jsonb_build_object(
+    'name', CASE WHEN filters ? 'name' THEN jsonb_build_object('contains', filters -> 'name', 'normalized', filters -> 'name_normalized') END,
+    'surname', CASE WHEN filters ? 'surname' THEN jsonb_build_object('contains', filters -> 'surname', 'normalized', filters -> 'surname_normalized') END,
+    'age', CASE WHEN filters ? 'age' THEN jsonb_build_object('eq', filters -> 'age') END,
+    'gender', CASE WHEN filters ? 'gender' THEN jsonb_build_object('eq', filters -> 'gender') END,
+    'nationality', CASE WHEN filters ? 'nationality' THEN jsonb_build_object('contains', filters -> 'nationality') END,
+    'enrichment_status', CASE WHEN filters ? 'enrichment_status' THEN jsonb_build_object('eq', filters -> 'enrichment_status') END,
+    'gender_probability', CASE WHEN filters ? 'gender_probability_gte' THEN jsonb_build_object('min', filters -> 'gender_probability_gte') END,
+    'nationality_probability', CASE WHEN filters ? 'nationality_probability_gte' THEN jsonb_build_object('min', filters -> 'nationality_probability_gte') END
+));
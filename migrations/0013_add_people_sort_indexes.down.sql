@@ -0,0 +1,6 @@
+DROP INDEX idx_people_created_at_id;
+DROP INDEX idx_people_nationality_id;
+DROP INDEX idx_people_gender_id;
+DROP INDEX idx_people_age_id;
+DROP INDEX idx_people_surname_id;
+DROP INDEX idx_people_name_id;
@@ -0,0 +1,7 @@
+-- Sort keys of GET /api/people with id as tiebreaker. Nullable columns are indexed the way they are sorted.
+CREATE INDEX idx_people_name_id ON people (name, id);
+CREATE INDEX idx_people_surname_id ON people (surname, id);
+CREATE INDEX idx_people_age_id ON people ((COALESCE(age, 0)), id);
+CREATE INDEX idx_people_gender_id ON people ((COALESCE(gender, '')), id);
+CREATE INDEX idx_people_nationality_id ON people ((COALESCE(nationality, '')), id);
+CREATE INDEX idx_people_created_at_id ON people (created_at, id);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_people_search_vector;
+DROP INDEX IF EXISTS idx_people_search_text_trgm;
+
+ALTER TABLE people
+    DROP COLUMN IF EXISTS search_vector,
+    DROP COLUMN IF EXISTS search_text;
+
+-- pg_trgm is kept: it may have been installed before and other objects may depend on it
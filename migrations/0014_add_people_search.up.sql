@@ -0,0 +1,16 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Names in their original and normalized forms for GET /api/people/search: trigram matching tolerates typos,
+-- the tsvector finds whole words regardless of their order.
+ALTER TABLE people
+    ADD COLUMN search_text TEXT GENERATED ALWAYS AS (
+        lower(name || ' ' || surname || ' ' || COALESCE(patronymic, '') || ' ' ||
+              name_normalized || ' ' || surname_normalized || ' ' || COALESCE(patronymic_normalized, ''))
+    ) STORED,
+    ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+        to_tsvector('simple', name || ' ' || surname || ' ' || COALESCE(patronymic, '') || ' ' ||
+                              name_normalized || ' ' || surname_normalized || ' ' || COALESCE(patronymic_normalized, ''))
+    ) STORED;
+
+CREATE INDEX idx_people_search_text_trgm ON people USING GIN (search_text gin_trgm_ops);
+CREATE INDEX idx_people_search_vector ON people USING GIN (search_vector);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_people_name_normalized_trgm;
+DROP INDEX IF EXISTS idx_people_surname_normalized_trgm;
+DROP INDEX IF EXISTS idx_people_normalized_names;
+
+DROP TABLE IF EXISTS person_merges;
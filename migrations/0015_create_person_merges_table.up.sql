@@ -0,0 +1,17 @@
+-- Audit trail of merged duplicates. merged_id no longer exists in people; lookups of it lead to survivor_id.
+-- survivor_id has no foreign key so that the trail outlives the survivor.
+CREATE TABLE person_merges (
+    id BIGSERIAL PRIMARY KEY,
+    survivor_id INTEGER NOT NULL,
+    merged_id INTEGER NOT NULL UNIQUE,
+    fields TEXT[] NOT NULL DEFAULT '{}',
+    merged JSONB NOT NULL,
+    merged_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_person_merges_survivor_id ON person_merges (survivor_id);
+
+-- Duplicate detection: equal normalized names, or similar ones through pg_trgm (see migration 0014)
+CREATE INDEX idx_people_normalized_names ON people (surname_normalized, name_normalized);
+CREATE INDEX idx_people_surname_normalized_trgm ON people USING GIN (surname_normalized gin_trgm_ops);
+CREATE INDEX idx_people_name_normalized_trgm ON people USING GIN (name_normalized gin_trgm_ops);
@@ -0,0 +1,5 @@
+-- People deleted softly would come back; remove them for good first
+DELETE FROM people WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_people_deleted_at;
+ALTER TABLE people DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,4 @@
+-- Soft delete: deleted people are left out of every read and purged for good after the retention period
+ALTER TABLE people ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX idx_people_deleted_at ON people (deleted_at) WHERE deleted_at IS NOT NULL;
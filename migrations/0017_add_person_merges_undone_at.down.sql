@@ -0,0 +1,7 @@
+-- Undone merges were deleted before this migration
+DELETE FROM person_merges WHERE undone_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_person_merges_merged_id;
+ALTER TABLE person_merges ADD CONSTRAINT person_merges_merged_id_key UNIQUE (merged_id);
+
+ALTER TABLE person_merges DROP COLUMN IF EXISTS undone_at;
@@ -0,0 +1,7 @@
+-- Merged duplicates are deleted softly in people. Restoring one marks its merge undone instead of
+-- removing it from the trail; lookups only follow merges that are not undone.
+ALTER TABLE person_merges ADD COLUMN undone_at TIMESTAMP;
+
+-- A person merged again after being restored gets a new row
+ALTER TABLE person_merges DROP CONSTRAINT person_merges_merged_id_key;
+CREATE UNIQUE INDEX idx_person_merges_merged_id ON person_merges (merged_id) WHERE undone_at IS NULL;